@@ -0,0 +1,22 @@
+package configloader
+
+import "fmt"
+
+// AfterLoader can be implemented by targets to run code once loading
+// is done, like computing derived fields or opening resources.
+// AfterLoad is called after every hook ran and the target passed
+// validation, and its error is returned from Retrieve.
+type AfterLoader interface {
+	AfterLoad() error
+}
+
+func afterLoad(target interface{}) error {
+	loaded, ok := target.(AfterLoader)
+	if !ok {
+		return nil
+	}
+	if err := loaded.AfterLoad(); err != nil {
+		return fmt.Errorf("error after loading config: %w", err)
+	}
+	return nil
+}
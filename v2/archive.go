@@ -0,0 +1,102 @@
+package configloader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ArchiveHook will load data from a JSON file inside a zip or
+// tar.gz archive, without extracting it to disk.
+type ArchiveHook struct {
+	archive string
+	entry   string
+}
+
+// CreateArchiveHook creates a hook which reads the file named entry,
+// like "components/api.json", from archive and decodes it like
+// CreateFileHook. The format is chosen by the archive extension:
+// .zip, .tar.gz or .tgz.
+func CreateArchiveHook(archive, entry string) ArchiveHook {
+	return ArchiveHook{archive: archive, entry: entry}
+}
+
+func (hook ArchiveHook) source() string {
+	return "file"
+}
+
+func (hook ArchiveHook) run(loader *ConfigLoader) error {
+	var data []byte
+	var err error
+	switch name := strings.ToLower(hook.archive); {
+	case strings.HasSuffix(name, ".zip"):
+		data, err = hook.readZip()
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		data, err = hook.readTarGz()
+	default:
+		return fmt.Errorf("unsupported archive %s: expected .zip, .tar.gz or .tgz", hook.archive)
+	}
+	if err != nil {
+		return fmt.Errorf("error while reading %s from archive %s: %w", hook.entry, hook.archive, err)
+	}
+	if err := loader.decodeJSON(data, false); err != nil {
+		return fmt.Errorf("error while decoding %s from archive %s: %w", hook.entry, hook.archive, err)
+	}
+	return nil
+}
+
+func (hook ArchiveHook) readZip() ([]byte, error) {
+	archive, err := zip.OpenReader(hook.archive)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	for _, file := range archive.File {
+		if !hook.matches(file.Name) {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	}
+	return nil, fmt.Errorf("entry not found")
+}
+
+func (hook ArchiveHook) readTarGz() ([]byte, error) {
+	file, err := os.Open(hook.archive)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	compressed, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer compressed.Close()
+	archive := tar.NewReader(compressed)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("entry not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && hook.matches(header.Name) {
+			return io.ReadAll(archive)
+		}
+	}
+}
+
+// matches compares entry names ignoring a leading "./", which tar
+// adds when archiving the current directory.
+func (hook ArchiveHook) matches(name string) bool {
+	return strings.TrimPrefix(name, "./") == strings.TrimPrefix(hook.entry, "./")
+}
@@ -0,0 +1,80 @@
+package configloader
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+)
+
+// isValueStruct reports whether typ is a struct loaded as a single
+// value, like time.Time, instead of field by field. Structs which
+// implement encoding.TextUnmarshaler are too.
+func isValueStruct(typ reflect.Type) bool {
+	return typ == timeType || typ == bigIntType || typ == bigFloatType || typ == regexpType ||
+		(typ.Kind() == reflect.Struct && isTextUnmarshaler(typ))
+}
+
+// setBig sets big.Int and big.Float fields, and pointers to them,
+// for values which don't fit in int64 or float64. It reports false
+// if value is of another type.
+func setBig(value reflect.Value, rawValue string) (bool, error) {
+	typ := value.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ {
+	case bigIntType:
+		parsed, ok := new(big.Int).SetString(rawValue, 10)
+		if !ok {
+			return true, fmt.Errorf("value '%s' is not a valid integer", rawValue)
+		}
+		setBigValue(value, reflect.ValueOf(parsed))
+		return true, nil
+	case bigFloatType:
+		parsed, ok := new(big.Float).SetPrec(bigFloatPrecision(rawValue)).SetString(rawValue)
+		if !ok {
+			return true, fmt.Errorf("value '%s' is not a valid number", rawValue)
+		}
+		setBigValue(value, reflect.ValueOf(parsed))
+		return true, nil
+	}
+	return false, nil
+}
+
+// bigFloatPrecision returns enough bits to keep every digit of
+// rawValue, and never less than float64 has.
+func bigFloatPrecision(rawValue string) uint {
+	const bitsPerDigit = 4
+	precision := uint(len(rawValue)) * bitsPerDigit
+	if precision < 64 {
+		return 64
+	}
+	return precision
+}
+
+func setBigValue(value, parsed reflect.Value) {
+	if value.Kind() == reflect.Ptr {
+		value.Set(parsed)
+		return
+	}
+	value.Set(parsed.Elem())
+}
+
+// copyBig copies big numbers, which keep their digits in unexported
+// slices that deepCopy would share.
+func copyBig(value reflect.Value) (reflect.Value, bool) {
+	switch value.Type() {
+	case bigIntType:
+		number := value.Interface().(big.Int)
+		return reflect.ValueOf(new(big.Int).Set(&number)).Elem(), true
+	case bigFloatType:
+		number := value.Interface().(big.Float)
+		return reflect.ValueOf(new(big.Float).Copy(&number)).Elem(), true
+	}
+	return value, false
+}
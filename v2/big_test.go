@@ -0,0 +1,78 @@
+package configloader
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+type bigConfig struct {
+	Supply    big.Int
+	Cap       *big.Int
+	Rate      big.Float
+	Threshold *big.Float
+}
+
+const (
+	hugeInt   = "-123456789012345678901234567890123456789012345678901234567890"
+	hugeFloat = "3.14159265358979323846264338327950288419716939937510582097494459"
+)
+
+func TestBigNumbersKeepEveryDigit(t *testing.T) {
+	var config bigConfig
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(CreateTestHook(map[string]string{
+			"Supply":    hugeInt,
+			"Cap":       "99999999999999999999999999",
+			"Rate":      hugeFloat,
+			"Threshold": "1e400",
+		})).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Supply.String() != hugeInt {
+		t.Errorf("expected Supply %s, got %s", hugeInt, config.Supply.String())
+	}
+	if config.Cap == nil || config.Cap.String() != "99999999999999999999999999" {
+		t.Errorf("unexpected Cap %v", config.Cap)
+	}
+	if got := config.Rate.Text('f', 62); got != hugeFloat {
+		t.Errorf("expected Rate %s, got %s", hugeFloat, got)
+	}
+	if config.Threshold == nil || config.Threshold.Text('e', 0) != "1e+400" {
+		t.Errorf("unexpected Threshold %v", config.Threshold)
+	}
+}
+
+func TestBigNumbersRejectInvalidValues(t *testing.T) {
+	cases := []struct {
+		field    string
+		raw      string
+		expected string
+	}{
+		{"Supply", "1.5", "field 'Supply': value '1.5' is not a valid integer"},
+		{"Cap", "0x10", "field 'Cap': value '0x10' is not a valid integer"},
+		{"Rate", "x", "field 'Rate': value 'x' is not a valid number"},
+		{"Threshold", "1e", "field 'Threshold': value '1e' is not a valid number"},
+	}
+	for _, test := range cases {
+		var config bigConfig
+		_, err := NewConfigLoaderFor(&config).
+			AddHook(CreateTestHook(map[string]string{test.field: test.raw})).
+			SafeRetrieve()
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("%s: expected %q, got %v", test.field, test.expected, err)
+		}
+	}
+}
+
+func TestBigNumbersAreSingleFields(t *testing.T) {
+	var names []string
+	for _, meta := range getTypeFields(reflect.TypeOf(bigConfig{})) {
+		names = append(names, meta.name)
+	}
+	if !reflect.DeepEqual(names, []string{"Supply", "Cap", "Rate", "Threshold"}) {
+		t.Errorf("expected one field per number, got %v", names)
+	}
+}
@@ -0,0 +1,265 @@
+package configloader
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	binaryPlistMagic   = "bplist00"
+	binaryPlistTrailer = 32
+)
+
+// maxBinaryPlistSeconds is the largest offset from binaryPlistEpoch, in
+// seconds, that fits a time.Duration.
+const maxBinaryPlistSeconds = float64(math.MaxInt64 / int64(time.Second))
+
+// binaryPlistEpoch is the reference date of binary plist dates.
+var binaryPlistEpoch = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+type binaryPlist struct {
+	data     []byte
+	offsets  []uint64
+	refSize  int
+	decoding map[uint64]bool
+}
+
+func decodeBinaryPlist(data []byte) (interface{}, error) {
+	if len(data) < len(binaryPlistMagic)+binaryPlistTrailer {
+		return nil, fmt.Errorf("binary plist is too short")
+	}
+	trailer := data[len(data)-binaryPlistTrailer:]
+	offsetSize := int(trailer[6])
+	plist := &binaryPlist{
+		data:     data,
+		refSize:  int(trailer[7]),
+		decoding: make(map[uint64]bool),
+	}
+	count := binary.BigEndian.Uint64(trailer[8:16])
+	top := binary.BigEndian.Uint64(trailer[16:24])
+	tableOffset := binary.BigEndian.Uint64(trailer[24:32])
+	// Sizes are at most 8 and count at most len(data), so the product
+	// can't overflow, and subtracting keeps the check in bounds.
+	limit := uint64(len(data) - binaryPlistTrailer)
+	if offsetSize == 0 || offsetSize > 8 || plist.refSize == 0 || plist.refSize > 8 ||
+		count > uint64(len(data)) || tableOffset > limit || count*uint64(offsetSize) > limit-tableOffset {
+		return nil, fmt.Errorf("invalid binary plist trailer")
+	}
+	plist.offsets = make([]uint64, count)
+	for i := range plist.offsets {
+		start := tableOffset + uint64(i*offsetSize)
+		plist.offsets[i] = readBigEndian(data[start : start+uint64(offsetSize)])
+	}
+	return plist.object(top)
+}
+
+func readBigEndian(data []byte) uint64 {
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+func (plist *binaryPlist) bytes(offset, length uint64) ([]byte, error) {
+	if offset > uint64(len(plist.data)) || length > uint64(len(plist.data))-offset {
+		return nil, fmt.Errorf("binary plist object out of bounds")
+	}
+	return plist.data[offset : offset+length], nil
+}
+
+func (plist *binaryPlist) object(ref uint64) (interface{}, error) {
+	if ref >= uint64(len(plist.offsets)) {
+		return nil, fmt.Errorf("invalid binary plist reference %d", ref)
+	}
+	if plist.decoding[ref] {
+		return nil, fmt.Errorf("cycle in binary plist at object %d", ref)
+	}
+	plist.decoding[ref] = true
+	defer delete(plist.decoding, ref)
+	offset := plist.offsets[ref]
+	marker, err := plist.bytes(offset, 1)
+	if err != nil {
+		return nil, err
+	}
+	kind, info := marker[0]>>4, marker[0]&0x0F
+	offset++
+	switch kind {
+	case 0x0:
+		switch info {
+		case 0x8:
+			return false, nil
+		case 0x9:
+			return true, nil
+		}
+		return nil, nil
+	case 0x1:
+		return plist.integer(offset, info)
+	case 0x2:
+		return plist.real(offset, info)
+	case 0x3:
+		raw, err := plist.bytes(offset, 8)
+		if err != nil {
+			return nil, err
+		}
+		seconds := math.Float64frombits(binary.BigEndian.Uint64(raw))
+		if math.IsNaN(seconds) || math.Abs(seconds) > maxBinaryPlistSeconds {
+			return nil, fmt.Errorf("binary plist date out of range")
+		}
+		date := binaryPlistEpoch.Add(time.Duration(seconds * float64(time.Second)))
+		return date.Format(time.RFC3339), nil
+	}
+	length, offset, err := plist.length(offset, info)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case 0x4:
+		raw, err := plist.bytes(offset, length)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(raw), nil
+	case 0x5:
+		raw, err := plist.bytes(offset, length)
+		if err != nil {
+			return nil, err
+		}
+		return string(raw), nil
+	case 0x6:
+		if length > uint64(len(plist.data)) {
+			return nil, fmt.Errorf("binary plist object out of bounds")
+		}
+		raw, err := plist.bytes(offset, length*2)
+		if err != nil {
+			return nil, err
+		}
+		units := make([]uint16, length)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(raw[i*2:])
+		}
+		return string(utf16.Decode(units)), nil
+	case 0xA:
+		return plist.array(offset, length)
+	case 0xD:
+		return plist.dict(offset, length)
+	}
+	return nil, fmt.Errorf("unsupported binary plist object type 0x%x", kind)
+}
+
+func (plist *binaryPlist) integer(offset uint64, info byte) (interface{}, error) {
+	size := uint64(1) << info
+	raw, err := plist.bytes(offset, size)
+	if err != nil {
+		return nil, err
+	}
+	switch size {
+	case 1, 2, 4:
+		return json.Number(strconv.FormatUint(readBigEndian(raw), 10)), nil
+	case 8:
+		return json.Number(strconv.FormatInt(int64(readBigEndian(raw)), 10)), nil
+	}
+	return nil, fmt.Errorf("unsupported binary plist integer size %d", size)
+}
+
+func (plist *binaryPlist) real(offset uint64, info byte) (interface{}, error) {
+	size := uint64(1) << info
+	raw, err := plist.bytes(offset, size)
+	if err != nil {
+		return nil, err
+	}
+	switch size {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(raw))), nil
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(raw)), nil
+	}
+	return nil, fmt.Errorf("unsupported binary plist real size %d", size)
+}
+
+// length reads the length of a data, string or collection object.
+// Lengths of 15 or more are stored as an integer object after the
+// marker. It returns the length and the offset of the contents.
+func (plist *binaryPlist) length(offset uint64, info byte) (uint64, uint64, error) {
+	if info != 0x0F {
+		return uint64(info), offset, nil
+	}
+	marker, err := plist.bytes(offset, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	size := uint64(1) << (marker[0] & 0x0F)
+	if marker[0]>>4 != 0x1 || size > 8 {
+		return 0, 0, fmt.Errorf("invalid binary plist length")
+	}
+	raw, err := plist.bytes(offset+1, size)
+	if err != nil {
+		return 0, 0, err
+	}
+	return readBigEndian(raw), offset + 1 + size, nil
+}
+
+func (plist *binaryPlist) refs(offset, count uint64) ([]uint64, error) {
+	size := uint64(plist.refSize)
+	if count > uint64(len(plist.data)) {
+		return nil, fmt.Errorf("binary plist object out of bounds")
+	}
+	raw, err := plist.bytes(offset, count*size)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]uint64, count)
+	for i := range refs {
+		refs[i] = readBigEndian(raw[uint64(i)*size : uint64(i+1)*size])
+	}
+	return refs, nil
+}
+
+func (plist *binaryPlist) array(offset, count uint64) (interface{}, error) {
+	refs, err := plist.refs(offset, count)
+	if err != nil {
+		return nil, err
+	}
+	array := make([]interface{}, 0, count)
+	for _, ref := range refs {
+		value, err := plist.object(ref)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+	}
+	return array, nil
+}
+
+func (plist *binaryPlist) dict(offset, count uint64) (interface{}, error) {
+	if count > uint64(len(plist.data)) {
+		return nil, fmt.Errorf("binary plist object out of bounds")
+	}
+	refs, err := plist.refs(offset, count*2)
+	if err != nil {
+		return nil, err
+	}
+	dict := make(map[string]interface{}, count)
+	for i := uint64(0); i < count; i++ {
+		key, err := plist.object(refs[i])
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("binary plist dict key is not a string")
+		}
+		value, err := plist.object(refs[count+i])
+		if err != nil {
+			return nil, err
+		}
+		dict[name] = value
+	}
+	return dict, nil
+}
@@ -0,0 +1,104 @@
+package configloader
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// buildBinaryPlist writes objects as a binary plist with one byte
+// offsets and refs, the first object being the top one.
+func buildBinaryPlist(objects ...[]byte) []byte {
+	data := []byte(binaryPlistMagic)
+	offsets := make([]byte, 0, len(objects))
+	for _, object := range objects {
+		offsets = append(offsets, byte(len(data)))
+		data = append(data, object...)
+	}
+	tableOffset := len(data)
+	data = append(data, offsets...)
+	trailer := make([]byte, binaryPlistTrailer)
+	trailer[6] = 1
+	trailer[7] = 1
+	binary.BigEndian.PutUint64(trailer[8:], uint64(len(objects)))
+	binary.BigEndian.PutUint64(trailer[24:], uint64(tableOffset))
+	return append(data, trailer...)
+}
+
+// samplePlist is {"Name": "x", "Port": 8080}.
+func samplePlist() []byte {
+	return buildBinaryPlist(
+		[]byte{0xD2, 1, 2, 3, 4},
+		append([]byte{0x54}, "Name"...),
+		append([]byte{0x54}, "Port"...),
+		[]byte{0x51, 'x'},
+		[]byte{0x11, 0x1F, 0x90},
+	)
+}
+
+func TestBinaryPlistLoads(t *testing.T) {
+	var config struct {
+		Name string
+		Port int
+	}
+	file := writeTestFile(t, "config.plist", string(samplePlist()))
+	_, err := NewConfigLoaderFor(&config).AddHook(CreatePlistHook(file)).SafeRetrieve()
+	if err != nil || config.Name != "x" || config.Port != 8080 {
+		t.Fatalf("expected x and 8080, got %+v (%v)", config, err)
+	}
+}
+
+func TestBinaryPlistRejectsWrappingTableOffset(t *testing.T) {
+	data := samplePlist()
+	binary.BigEndian.PutUint64(data[len(data)-8:], math.MaxUint64)
+	if _, err := decodeBinaryPlist(data); err == nil || err.Error() != "invalid binary plist trailer" {
+		t.Fatalf("expected an invalid trailer error, got %v", err)
+	}
+}
+
+func TestBinaryPlistRejectsHugeUTF16Length(t *testing.T) {
+	// A UTF-16 string whose length, an 8 byte int, would wrap when doubled.
+	object := []byte{0x6F, 0x13, 0x80, 0, 0, 0, 0, 0, 0, 1}
+	if _, err := decodeBinaryPlist(buildBinaryPlist(object)); err == nil {
+		t.Fatal("expected an out of bounds error")
+	}
+}
+
+func TestBinaryPlistRejectsOutOfRangeDate(t *testing.T) {
+	for _, seconds := range []float64{math.Inf(1), math.NaN(), 1e300} {
+		object := make([]byte, 9)
+		object[0] = 0x33
+		binary.BigEndian.PutUint64(object[1:], math.Float64bits(seconds))
+		if _, err := decodeBinaryPlist(buildBinaryPlist(object)); err == nil || !strings.Contains(err.Error(), "date out of range") {
+			t.Errorf("%v seconds: expected a date error, got %v", seconds, err)
+		}
+	}
+}
+
+func TestBinaryPlistRejects16ByteInts(t *testing.T) {
+	object := append([]byte{0x14}, make([]byte, 16)...)
+	if _, err := decodeBinaryPlist(buildBinaryPlist(object)); err == nil || !strings.Contains(err.Error(), "integer size 16") {
+		t.Fatalf("expected an integer size error, got %v", err)
+	}
+}
+
+func TestBinaryPlistCorruptInputDoesNotPanic(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	valid := samplePlist()
+	for i := 0; i < 20000; i++ {
+		data := append([]byte{}, valid...)
+		for j := random.Intn(4); j >= 0; j-- {
+			data[len(binaryPlistMagic)+random.Intn(len(data)-len(binaryPlistMagic))] = byte(random.Intn(256))
+		}
+		func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					t.Fatalf("panic decoding %x: %v", data, recovered)
+				}
+			}()
+			decodeBinaryPlist(data)
+		}()
+	}
+}
@@ -0,0 +1,77 @@
+package configloader
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type cacheNested struct {
+	Host string
+	Port int
+}
+
+type cacheConfig struct {
+	Name     string
+	Database cacheNested `configPrefix:"db"`
+	Backup   *cacheNested
+	Tags     []string
+	Limits   map[string]int
+}
+
+func TestTypeFieldsAreCached(t *testing.T) {
+	typ := reflect.TypeOf(cacheConfig{})
+	first := getTypeFields(typ)
+	second := getTypeFields(typ)
+	if len(first) == 0 || &first[0] != &second[0] {
+		t.Fatal("expected the second walk to reuse the cached fields")
+	}
+	var names []string
+	for _, meta := range first {
+		names = append(names, meta.name)
+	}
+	expected := []string{"Name", "dbHost", "dbPort", "Host", "Port", "Tags", "Limits"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected fields %v, got %v", expected, names)
+	}
+}
+
+func TestCachedFieldsLoadEveryTarget(t *testing.T) {
+	for _, port := range []int{1, 2} {
+		var config cacheConfig
+		_, err := NewConfigLoaderFor(&config).
+			AddHook(CreateTestHook(map[string]string{"dbPort": strconv.Itoa(port)})).
+			SafeRetrieve()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.Database.Port != port {
+			t.Errorf("expected port %d, got %d", port, config.Database.Port)
+		}
+		if config.Backup != nil {
+			t.Error("expected Backup to stay nil")
+		}
+	}
+}
+
+func benchmarkForeachField(b *testing.B, cached bool) {
+	var config cacheConfig
+	typ := reflect.TypeOf(config)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !cached {
+			fieldsCache.Delete(typ)
+		}
+		if err := foreachField(&config, func(currentField) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkForeachFieldCached(b *testing.B) {
+	benchmarkForeachField(b, true)
+}
+
+func BenchmarkForeachFieldUncached(b *testing.B) {
+	benchmarkForeachField(b, false)
+}
@@ -0,0 +1,55 @@
+package configloader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+)
+
+// CompressedEnvHook will load a JSON document stored in a single
+// env var as base64 encoded gzip data. This keeps big configs under
+// the env size limits of some platforms. Generate the value with:
+//
+//	gzip -c config.json | base64 -w0
+type CompressedEnvHook struct {
+	name string
+}
+
+// CreateCompressedEnvHook creates a hook which reads the env var
+// name, decodes and decompresses it, and decodes the resulting JSON
+// into the target like CreateFileHook. If the var is not set, this
+// hook does nothing.
+func CreateCompressedEnvHook(name string) CompressedEnvHook {
+	return CompressedEnvHook{name: name}
+}
+
+func (hook CompressedEnvHook) source() string {
+	return "env"
+}
+
+func (hook CompressedEnvHook) run(loader *ConfigLoader) error {
+	encoded, ok := os.LookupEnv(hook.name)
+	if !ok {
+		return nil
+	}
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("env var %s is not valid base64: %w", hook.name, err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return fmt.Errorf("env var %s is not valid gzip data: %w", hook.name, err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("error while decompressing env var %s: %w", hook.name, err)
+	}
+	if err := loader.decodeJSON(data, false); err != nil {
+		return fmt.Errorf("error while decoding env var %s: %w", hook.name, err)
+	}
+	return nil
+}
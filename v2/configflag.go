@@ -0,0 +1,63 @@
+package configloader
+
+import (
+	"os"
+	"strings"
+)
+
+// ConfigFlagHook will load data from the JSON file named by a command
+// line flag, like -config ./prod.json.
+type ConfigFlagHook struct {
+	flag        string
+	defaultPath string
+}
+
+// CreateConfigFlagHook creates a hook which reads the flag with the
+// passed name (accepting -name value, -name=value and the same with
+// two dashes) and loads that file like CreateFileHook. If the flag is
+// missing, defaultPath is loaded instead; if it is empty too, the hook
+// does nothing.
+//
+// Add it before ParamsHook, so values from the file are loaded first
+// and other flags override them. ParamsHook knows about the flag, so
+// it does not complain about it.
+func CreateConfigFlagHook(flag, defaultPath string) ConfigFlagHook {
+	return ConfigFlagHook{flag: flag, defaultPath: defaultPath}
+}
+
+func (hook ConfigFlagHook) source() string {
+	return "file"
+}
+
+func (hook ConfigFlagHook) run(loader *ConfigLoader) error {
+	loader.extraFlags = append(loader.extraFlags, hook.flag)
+	path, ok := lookupArg(os.Args[1:], hook.flag)
+	if !ok {
+		path = hook.defaultPath
+	}
+	if len(path) == 0 {
+		return nil
+	}
+	return CreateFileHook(path).run(loader)
+}
+
+// lookupArg finds the value of a flag in args without parsing the
+// rest of them. It stops at the "--" terminator.
+func lookupArg(args []string, name string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			return "", false
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		arg = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if arg == name && i+1 < len(args) {
+			return args[i+1], true
+		}
+		if strings.HasPrefix(arg, name+"=") {
+			return strings.TrimPrefix(arg, name+"="), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,106 @@
+package configloader
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// configTagOptions maps the options of the combined config tag to
+// the individual tags they stand for.
+var configTagOptions = map[string]string{
+	"env":      "configEnv",
+	"default":  "configDefault",
+	"required": "configRequired",
+	"secret":   "configSecret",
+	"name":     "configName",
+	"prefix":   "configPrefix",
+	"group":    "configGroup",
+}
+
+// expandConfigTag rewrites the combined config tag of field, like
+// config:"env=DB_PORT,default=5432,required", into the individual
+// tags, so the rest of the loader only reads those. Individual tags
+// already on the field win over the combined one. Values containing
+// commas must be quoted with single quotes: default='a,b'. A
+// configGroup also stands for the configPrefix, if there is none.
+func expandConfigTag(field reflect.StructField) (reflect.StructField, error) {
+	field, err := expandCombinedTag(field)
+	if err != nil {
+		return field, err
+	}
+	if group, ok := field.Tag.Lookup("configGroup"); ok {
+		if _, ok := field.Tag.Lookup("configPrefix"); !ok {
+			field.Tag = reflect.StructTag(fmt.Sprintf("%s configPrefix:%s", field.Tag, strconv.Quote(group)))
+		}
+	}
+	return field, nil
+}
+
+func expandCombinedTag(field reflect.StructField) (reflect.StructField, error) {
+	combined, ok := field.Tag.Lookup("config")
+	if !ok {
+		return field, nil
+	}
+	options, err := splitConfigTag(combined)
+	if err != nil {
+		return field, fmt.Errorf("invalid config tag: %w", err)
+	}
+	tag := string(field.Tag)
+	for _, option := range options {
+		pair := strings.SplitN(option, "=", 2)
+		key := strings.TrimSpace(pair[0])
+		name, ok := configTagOptions[key]
+		if !ok {
+			return field, fmt.Errorf("invalid config tag: unknown option '%s'", key)
+		}
+		value := "true"
+		if len(pair) == 2 {
+			value = unquoteOption(strings.TrimSpace(pair[1]))
+		}
+		if _, ok := field.Tag.Lookup(name); !ok {
+			tag = fmt.Sprintf("%s %s:%s", tag, name, strconv.Quote(value))
+		}
+	}
+	field.Tag = reflect.StructTag(strings.TrimSpace(tag))
+	return field, nil
+}
+
+// unquoteOption removes the single quotes around an option value,
+// like 'a,b'. Only one pair is removed, so quotes inside the value,
+// or at only one of its ends, are kept.
+func unquoteOption(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// splitConfigTag splits the options of a config tag by commas,
+// except for the ones inside single quotes.
+func splitConfigTag(tag string) ([]string, error) {
+	options := make([]string, 0)
+	quoted := false
+	start := 0
+	for i, char := range tag {
+		switch {
+		case char == '\'':
+			quoted = !quoted
+		case char == ',' && !quoted:
+			options = append(options, tag[start:i])
+			start = i + 1
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unclosed quote in '%s'", tag)
+	}
+	options = append(options, tag[start:])
+	filtered := make([]string, 0, len(options))
+	for _, option := range options {
+		if len(strings.TrimSpace(option)) > 0 {
+			filtered = append(filtered, option)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,49 @@
+package configloader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfigTagExpandsOptions(t *testing.T) {
+	field := reflect.StructField{Name: "Port", Tag: `config:"env=DB_PORT, default=5432,required" configDefault:"1"`}
+	expanded, err := expandConfigTag(field)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, expected := range map[string]string{"configEnv": "DB_PORT", "configDefault": "1", "configRequired": "true"} {
+		if got := expanded.Tag.Get(name); got != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, got)
+		}
+	}
+}
+
+func TestConfigTagUnquotesOnePair(t *testing.T) {
+	cases := []struct {
+		tag      reflect.StructTag
+		expected string
+	}{
+		{`config:"default='a,b'"`, "a,b"},
+		{`config:"default=''"`, ""},
+		{`config:"default=''''"`, "''"},
+		{`config:"default='it''s'"`, "it''s"},
+		{`config:"default=plain"`, "plain"},
+	}
+	for _, test := range cases {
+		expanded, err := expandConfigTag(reflect.StructField{Name: "Value", Tag: test.tag})
+		if err != nil {
+			t.Errorf("%s: %v", test.tag, err)
+			continue
+		}
+		if got := expanded.Tag.Get("configDefault"); got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.tag, test.expected, got)
+		}
+	}
+}
+
+func TestConfigTagRejectsUnclosedQuote(t *testing.T) {
+	field := reflect.StructField{Name: "Value", Tag: `config:"default='a,b"`}
+	if _, err := expandConfigTag(field); err == nil {
+		t.Error("expected an error for an unclosed quote")
+	}
+}
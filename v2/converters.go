@@ -0,0 +1,58 @@
+package configloader
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Converter parses a raw config value into a value of the type it
+// was registered for.
+type Converter func(rawValue string) (interface{}, error)
+
+var (
+	convertersMutex sync.RWMutex
+	converters      = make(map[reflect.Type]Converter)
+)
+
+// RegisterConverter registers a function to parse values for fields
+// of the type of value. Hooks that read strings (env vars, params,
+// flat and key value files...) use it for fields, slice items and map
+// keys and values of that type. This makes custom enums first class:
+//
+//	type Level int
+//
+//	configloader.RegisterConverter(Level(0), func(raw string) (interface{}, error) {
+//		return ParseLevel(raw)
+//	})
+//
+// With CONFIG_LEVEL=INFO the field will hold the Level returned by
+// ParseLevel. The converter must return a value assignable to the
+// type. Types are global, so register each one only once. JSON files
+// are decoded by encoding/json, so give the type an UnmarshalJSON
+// method to load it from them.
+func RegisterConverter(value interface{}, converter Converter) {
+	convertersMutex.Lock()
+	defer convertersMutex.Unlock()
+	converters[reflect.TypeOf(value)] = converter
+}
+
+func lookupConverter(typ reflect.Type) (Converter, bool) {
+	convertersMutex.RLock()
+	defer convertersMutex.RUnlock()
+	converter, ok := converters[typ]
+	return converter, ok
+}
+
+func setConverted(value reflect.Value, rawValue string, converter Converter) error {
+	converted, err := converter(rawValue)
+	if err != nil {
+		return fmt.Errorf("value '%s' is not a valid %s: %w", rawValue, value.Type(), err)
+	}
+	result := reflect.ValueOf(converted)
+	if !result.IsValid() || !result.Type().AssignableTo(value.Type()) {
+		return fmt.Errorf("converter for %s returned %T", value.Type(), converted)
+	}
+	value.Set(result)
+	return nil
+}
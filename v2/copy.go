@@ -0,0 +1,78 @@
+package configloader
+
+import "reflect"
+
+// deepCopy returns a copy of value that shares no maps, slices or
+// pointers with it. Unexported struct fields are copied as they are.
+// Pointer cycles, like a struct pointing back to its parent, are kept
+// as cycles in the copy instead of being followed forever.
+func deepCopy(value reflect.Value) reflect.Value {
+	return deepCopyValue(value, make(map[copiedPointer]reflect.Value))
+}
+
+// copiedPointer identifies a pointer already copied. The type is part
+// of it because a struct and its first field share the same address.
+type copiedPointer struct {
+	address uintptr
+	typ     reflect.Type
+}
+
+func deepCopyValue(value reflect.Value, copies map[copiedPointer]reflect.Value) reflect.Value {
+	if copied, ok := copyBig(value); ok {
+		return copied
+	}
+	result := reflect.New(value.Type()).Elem()
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return result
+		}
+		key := copiedPointer{address: value.Pointer(), typ: value.Type()}
+		if elem, ok := copies[key]; ok {
+			result.Set(elem)
+			return result
+		}
+		elem := reflect.New(value.Type().Elem())
+		copies[key] = elem
+		elem.Elem().Set(deepCopyValue(value.Elem(), copies))
+		result.Set(elem)
+	case reflect.Interface:
+		if value.IsNil() {
+			return result
+		}
+		result.Set(deepCopyValue(value.Elem(), copies))
+	case reflect.Slice:
+		if value.IsNil() {
+			return result
+		}
+		slice := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			slice.Index(i).Set(deepCopyValue(value.Index(i), copies))
+		}
+		result.Set(slice)
+	case reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			result.Index(i).Set(deepCopyValue(value.Index(i), copies))
+		}
+	case reflect.Map:
+		if value.IsNil() {
+			return result
+		}
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(deepCopyValue(iter.Key(), copies), deepCopyValue(iter.Value(), copies))
+		}
+		result.Set(copied)
+	case reflect.Struct:
+		result.Set(value)
+		for i := 0; i < value.NumField(); i++ {
+			if result.Field(i).CanSet() {
+				result.Field(i).Set(deepCopyValue(value.Field(i), copies))
+			}
+		}
+	default:
+		result.Set(value)
+	}
+	return result
+}
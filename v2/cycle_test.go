@@ -0,0 +1,68 @@
+package configloader
+
+import (
+	"strings"
+	"testing"
+)
+
+type cycleNode struct {
+	Name string
+	Next *cycleNode
+}
+
+type cycleParent struct {
+	Name  string
+	Child *cycleChild
+}
+
+type cycleChild struct {
+	Port   int
+	Parent *cycleParent
+}
+
+type cycleList struct {
+	Name string
+	next *cycleList
+}
+
+func TestLoadRejectsRecursivePointer(t *testing.T) {
+	var node cycleNode
+	_, err := NewConfigLoaderFor(&node).AddHook(CreateTestHook(map[string]string{"Name": "a"})).SafeRetrieve()
+	if err == nil || !strings.Contains(err.Error(), "field 'cycleNode.Next': recursive type *configloader.cycleNode") {
+		t.Fatalf("expected a recursive type error, got %v", err)
+	}
+}
+
+func TestLoadRejectsIndirectCycle(t *testing.T) {
+	var parent cycleParent
+	_, err := NewConfigLoaderFor(&parent).AddHook(CreateTestHook(map[string]string{})).SafeRetrieve()
+	if err == nil || !strings.Contains(err.Error(), "field 'cycleParent.Child.Parent': recursive type *configloader.cycleParent") {
+		t.Fatalf("expected a recursive type error, got %v", err)
+	}
+}
+
+func TestSchemaRejectsRecursivePointerLikeLoad(t *testing.T) {
+	_, schemaErr := GenerateJSONSchema(&cycleNode{})
+	var node cycleNode
+	_, loadErr := NewConfigLoaderFor(&node).AddHook(CreateTestHook(map[string]string{})).SafeRetrieve()
+	if schemaErr == nil || loadErr == nil || schemaErr.Error() != loadErr.Error() {
+		t.Fatalf("expected the same error, got schema %v and load %v", schemaErr, loadErr)
+	}
+}
+
+func TestUnexportedCycleIsIgnored(t *testing.T) {
+	var list cycleList
+	_, err := NewConfigLoaderFor(&list).AddHook(CreateTestHook(map[string]string{"Name": "a"})).SafeRetrieve()
+	if err != nil || list.Name != "a" {
+		t.Fatalf("expected Name to load, got %v and %+v", err, list)
+	}
+}
+
+func TestSnapshotKeepsCycles(t *testing.T) {
+	node := cycleNode{Name: "a"}
+	node.Next = &node
+	copied := NewConfigLoaderFor(&node).Snapshot().(*cycleNode)
+	if copied == &node || copied.Next != copied {
+		t.Fatalf("expected a cyclic copy, got %p with next %p", copied, copied.Next)
+	}
+}
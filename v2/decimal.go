@@ -0,0 +1,32 @@
+package configloader
+
+import (
+	"reflect"
+	"strings"
+)
+
+// WithDecimalComma makes float fields accept a comma as the decimal
+// separator, so "1,5" reads as 1.5. It only applies to single float
+// fields: lists keep using the comma to split items, so write their
+// items with a dot or change their configSeparator. Values with
+// thousands separators, like "1.234,5", are still an error.
+func (loader *ConfigLoader) WithDecimalComma() *ConfigLoader {
+	loader.decimalComma = true
+	return loader
+}
+
+// normalizeDecimal replaces the decimal comma of rawValue with a dot
+// for float fields, when the loader accepts decimal commas.
+func (loader *ConfigLoader) normalizeDecimal(field currentField, rawValue string) string {
+	if !loader.decimalComma || strings.Count(rawValue, ",") != 1 || strings.Contains(rawValue, ".") {
+		return rawValue
+	}
+	typ := field.value.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if _, ok := lookupConverter(typ); ok || (typ.Kind() != reflect.Float32 && typ.Kind() != reflect.Float64) {
+		return rawValue
+	}
+	return strings.Replace(rawValue, ",", ".", 1)
+}
@@ -0,0 +1,55 @@
+package configloader
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Decoder decodes the contents read from reader into target, which
+// is the pointer passed to NewConfigLoaderFor. It must fill target in
+// place, keeping the fields the data does not mention untouched, so
+// values loaded by earlier hooks are kept.
+type Decoder func(reader io.Reader, target interface{}) error
+
+// DecoderHook will load data from a file in any format, using the
+// passed Decoder.
+type DecoderHook struct {
+	file   string
+	decode Decoder
+}
+
+// CreateDecoderHook creates a hook which opens file and decodes it
+// with decode. Use it to plug any format (msgpack, cbor, protobuf...)
+// into the file loading flow:
+//
+//	configloader.CreateDecoderHook("./config.msgpack", func(reader io.Reader, target interface{}) error {
+//		return msgpack.NewDecoder(reader).Decode(target)
+//	})
+//
+// Finding the fields a decoder changed takes a copy of the target, so
+// it is only done when something needs them: OnFieldSet callbacks,
+// WithVerbose, WithNoImplicitZeros or configPrecedence tags. Without
+// them, the HookResult of the hook lists no fields.
+func CreateDecoderHook(file string, decode Decoder) DecoderHook {
+	return DecoderHook{file: file, decode: decode}
+}
+
+func (hook DecoderHook) source() string {
+	return "file"
+}
+
+func (hook DecoderHook) run(loader *ConfigLoader) error {
+	file, err := os.Open(hook.file)
+	if err != nil {
+		return fmt.Errorf("error while reading config file: %w", err)
+	}
+	defer file.Close()
+	err = loader.decodeTarget(func(target interface{}) error {
+		return hook.decode(file, target)
+	})
+	if err != nil {
+		return fmt.Errorf("error while decoding config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package configloader
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// DefaultsHook loads the values of the configDefault tags. Add it
+// before the other hooks, so any source can override the defaults.
+//
+// A default can reference other fields or env vars with ${NAME}:
+//
+//	BaseURL string `configDefault:"http://${HOST}:${PORT}"`
+//
+// Defaults without references are set when the hook runs. Defaults
+// with references are resolved after all the other hooks ran, and only
+// for fields that are still empty. Each NAME is looked up first as a
+// field name (ignoring case, as used by params) and then as an env var.
+// If the referenced field also has a pending default, it is resolved
+// first. Defaults referencing each other in a cycle are an error.
+type DefaultsHook struct{}
+
+// CreateDefaultsHook creates a hook which loads configDefault tags.
+func CreateDefaultsHook() DefaultsHook {
+	return DefaultsHook{}
+}
+
+var defaultReference = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+func (hook DefaultsHook) source() string {
+	return "default"
+}
+
+func (hook DefaultsHook) run(loader *ConfigLoader) error {
+	pending := make([]currentField, 0)
+	err := loader.foreachField(func(field currentField) error {
+		value, ok := field.original.Tag.Lookup("configDefault")
+		if !ok {
+			return nil
+		}
+		if defaultReference.MatchString(value) {
+			pending = append(pending, field)
+			return nil
+		}
+		return loader.setField(field, value)
+	})
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		loader.deferAction(func() error {
+			return newDefaultsResolver(loader, pending).resolveAll()
+		})
+	}
+	return nil
+}
+
+type defaultsResolver struct {
+	loader    *ConfigLoader
+	fields    map[string]currentField
+	pending   map[string]bool
+	resolving []string
+}
+
+func newDefaultsResolver(loader *ConfigLoader, pending []currentField) *defaultsResolver {
+	resolver := &defaultsResolver{
+		loader:  loader,
+		fields:  make(map[string]currentField),
+		pending: make(map[string]bool),
+	}
+	foreachField(loader.target, func(field currentField) error {
+		field = loader.prepareField(field)
+		resolver.fields[strings.ToLower(field.name)] = field
+		return nil
+	})
+	for _, field := range pending {
+		if field.value.IsZero() {
+			resolver.pending[strings.ToLower(field.name)] = true
+		}
+	}
+	return resolver
+}
+
+func (resolver *defaultsResolver) resolveAll() error {
+	for name := range resolver.pending {
+		if err := resolver.resolve(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (resolver *defaultsResolver) resolve(name string) error {
+	if !resolver.pending[name] {
+		return nil
+	}
+	for _, current := range resolver.resolving {
+		if current == name {
+			cycle := make([]string, 0, len(resolver.resolving)+1)
+			for _, step := range append(resolver.resolving, name) {
+				cycle = append(cycle, resolver.fields[step].name)
+			}
+			return fmt.Errorf("cycle in configDefault: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	resolver.resolving = append(resolver.resolving, name)
+	field := resolver.fields[name]
+	var err error
+	value := defaultReference.ReplaceAllStringFunc(field.original.Tag.Get("configDefault"), func(reference string) string {
+		if err != nil {
+			return ""
+		}
+		var resolved string
+		resolved, err = resolver.lookup(defaultReference.FindStringSubmatch(reference)[1])
+		return resolved
+	})
+	if err != nil {
+		return err
+	}
+	resolver.resolving = resolver.resolving[:len(resolver.resolving)-1]
+	delete(resolver.pending, name)
+	return resolver.loader.setField(field, value)
+}
+
+func (resolver *defaultsResolver) lookup(reference string) (string, error) {
+	name := strings.ToLower(reference)
+	field, ok := resolver.fields[name]
+	if !ok {
+		return os.Getenv(reference), nil
+	}
+	if err := resolver.resolve(name); err != nil {
+		return "", err
+	}
+	return formatValue(field.value), nil
+}
+
+// formatValue writes a field value as text, using MarshalText for
+// types implementing encoding.TextMarshaler.
+func formatValue(value reflect.Value) string {
+	if text, ok := marshalText(value); ok {
+		return text
+	}
+	return fmt.Sprint(value.Interface())
+}
@@ -0,0 +1,72 @@
+package configloader
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldInfo describes how a field of your configuration
+// struct is loaded.
+type FieldInfo struct {
+	// Name is the field name used by params, built from
+	// configPrefix and configName (or the field name).
+	Name string
+	// Path is the dotted path used by flat JSON files.
+	Path     string
+	Type     reflect.Type
+	EnvVar   string
+	Flag     string
+	Default  string
+	Required bool
+	Secret   bool
+}
+
+// Describe returns the metadata of each field of target, so you can
+// generate documentation from your configuration struct. Target must
+// be a pointer to a struct. Default, Required and Secret are read from
+// the configDefault, configRequired and configSecret tags.
+func Describe(target interface{}) []FieldInfo {
+	infos := make([]FieldInfo, 0)
+	foreachField(target, func(field currentField) error {
+		infos = append(infos, describeField(field))
+		return nil
+	})
+	return infos
+}
+
+func describeField(field currentField) FieldInfo {
+	return FieldInfo{
+		Name:     field.name,
+		Path:     strings.Join(field.path, "."),
+		Type:     field.original.Type,
+		EnvVar:   CreateEnvHook().envVarNames(field)[0],
+		Flag:     "-" + field.name,
+		Default:  field.original.Tag.Get("configDefault"),
+		Required: isTagEnabled(field.original.Tag, "configRequired"),
+		Secret:   isSecret(field),
+	}
+}
+
+// EnvVarName returns the env var that sets a field of target when
+// the env hook uses prefix, like "CONFIG_" for CreateEnvHook. The
+// field is named as used by params or by its dotted path. A configEnv
+// tag on the field is returned as is. If there is no such field, the
+// name is empty.
+func EnvVarName(target interface{}, fieldName, prefix string) string {
+	hook := CreateEnvHookWithPrefixes(prefix)
+	name := ""
+	foreachField(target, func(field currentField) error {
+		if field.name == fieldName || strings.Join(field.path, ".") == fieldName {
+			name = hook.envVarNames(field)[0]
+		}
+		return nil
+	})
+	return name
+}
+
+// isTagEnabled reports whether a boolean tag is set to a true value.
+func isTagEnabled(tag reflect.StructTag, key string) bool {
+	enabled, err := strconv.ParseBool(tag.Get(key))
+	return err == nil && enabled
+}
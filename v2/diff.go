@@ -0,0 +1,25 @@
+package configloader
+
+import "reflect"
+
+// Diff returns the names (as used by params) of the fields whose value
+// is different in old and new. Both must be pointers to structs of the
+// same type, like two configs loaded before and after a reload. Nested
+// structs are compared field by field; slices and maps by their contents.
+func Diff(old, new interface{}) []string {
+	oldValues := make([]reflect.Value, 0)
+	foreachField(old, func(field currentField) error {
+		oldValues = append(oldValues, field.value)
+		return nil
+	})
+	changed := make([]string, 0)
+	i := 0
+	foreachField(new, func(field currentField) error {
+		if !reflect.DeepEqual(oldValues[i].Interface(), field.value.Interface()) {
+			changed = append(changed, field.name)
+		}
+		i++
+		return nil
+	})
+	return changed
+}
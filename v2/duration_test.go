@@ -0,0 +1,125 @@
+package configloader
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadDuration(t *testing.T, rawValue string, tag reflect.StructTag) (time.Duration, error) {
+	t.Helper()
+	var duration time.Duration
+	err := setDuration(reflect.ValueOf(&duration).Elem(), rawValue, tag)
+	return duration, err
+}
+
+func TestDurationUnit(t *testing.T) {
+	cases := []struct {
+		raw      string
+		tag      reflect.StructTag
+		expected time.Duration
+	}{
+		{"30", `configDurationUnit:"seconds"`, 30 * time.Second},
+		{"1.5", `configDurationUnit:"seconds"`, 1500 * time.Millisecond},
+		{"0.25", `configDurationUnit:"hours"`, 15 * time.Minute},
+		{"2m", `configDurationUnit:"seconds"`, 2 * time.Minute},
+		{"1500", "", 1500 * time.Nanosecond},
+	}
+	for _, test := range cases {
+		duration, err := loadDuration(t, test.raw, test.tag)
+		if err != nil || duration != test.expected {
+			t.Errorf("%s with %s: expected %s, got %s (%v)", test.raw, test.tag, test.expected, duration, err)
+		}
+	}
+}
+
+func TestDurationRejectsPartialNanoseconds(t *testing.T) {
+	for _, raw := range []string{"1.5", "1.9", "1e-400"} {
+		if _, err := loadDuration(t, raw, ""); err == nil || !strings.Contains(err.Error(), "whole number of nanoseconds") {
+			t.Errorf("%s: expected a whole nanoseconds error, got %v", raw, err)
+		}
+	}
+}
+
+func TestDurationOverflow(t *testing.T) {
+	for _, raw := range []string{"3000000", "3000000.5", "1e300"} {
+		if _, err := loadDuration(t, raw, `configDurationUnit:"hours"`); err == nil || !strings.Contains(err.Error(), "overflows") {
+			t.Errorf("%s hours: expected an overflow error, got %v", raw, err)
+		}
+	}
+}
+
+func TestDurationStringOrInteger(t *testing.T) {
+	for _, raw := range []string{"30s", "30000000000", "0.5m"} {
+		duration, err := loadDuration(t, raw, "")
+		if err != nil || duration != 30*time.Second {
+			t.Errorf("%s: expected 30s, got %s (%v)", raw, duration, err)
+		}
+	}
+	if _, err := loadDuration(t, "abc", ""); err == nil || err.Error() != "value 'abc' is not a valid duration" {
+		t.Errorf("expected an invalid duration error, got %v", err)
+	}
+}
+
+type durationConfig struct {
+	RequestTimeout time.Duration
+	Backoff        []time.Duration
+}
+
+func loadDurationFile(t *testing.T, content string) (durationConfig, error) {
+	t.Helper()
+	file := writeTestFile(t, "config.json", content)
+	var config durationConfig
+	_, err := NewConfigLoaderFor(&config).AddHook(CreateFileHook(file)).SafeRetrieve()
+	return config, err
+}
+
+func TestJSONDurationStringOrNumber(t *testing.T) {
+	cases := []string{
+		`{"RequestTimeout": "30s"}`,
+		`{"RequestTimeout": 30000000000}`,
+		`{"RequestTimeout": "30000000000"}`,
+	}
+	for _, content := range cases {
+		config, err := loadDurationFile(t, content)
+		if err != nil || config.RequestTimeout != 30*time.Second {
+			t.Errorf("%s: expected 30s, got %s (%v)", content, config.RequestTimeout, err)
+		}
+	}
+	config, err := loadDurationFile(t, `{"Backoff": ["1s", 2000000000]}`)
+	if err != nil || !reflect.DeepEqual(config.Backoff, []time.Duration{time.Second, 2 * time.Second}) {
+		t.Errorf("expected [1s 2s], got %v (%v)", config.Backoff, err)
+	}
+}
+
+func TestJSONDurationInvalid(t *testing.T) {
+	cases := map[string]string{
+		`{"RequestTimeout": 1.9}`:   "value '1.9' is not a whole number of nanoseconds",
+		`{"RequestTimeout": "abc"}`: "value 'abc' is not a valid duration",
+		`{"RequestTimeout": true}`:  "value true is not a valid duration",
+	}
+	for content, expected := range cases {
+		if _, err := loadDurationFile(t, content); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected %q, got %v", content, expected, err)
+		}
+	}
+}
+
+func TestJSONDurationUnit(t *testing.T) {
+	var config struct {
+		Timeout time.Duration   `configDurationUnit:"seconds"`
+		Backoff []time.Duration `configDurationUnit:"milliseconds"`
+	}
+	file := writeTestFile(t, "config.json", `{"Timeout": 30, "Backoff": [100, "250", "1s"]}`)
+	if _, err := NewConfigLoaderFor(&config).AddHook(CreateFileHook(file)).SafeRetrieve(); err != nil {
+		t.Fatal(err)
+	}
+	if config.Timeout != 30*time.Second {
+		t.Errorf("expected 30s, got %s", config.Timeout)
+	}
+	expected := []time.Duration{100 * time.Millisecond, 250 * time.Millisecond, time.Second}
+	if !reflect.DeepEqual(config.Backoff, expected) {
+		t.Errorf("expected %v, got %v", expected, config.Backoff)
+	}
+}
@@ -0,0 +1,70 @@
+package configloader
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnumConverter builds a Converter for an integer enum type, the type
+// of value, which accepts both the names in names and their numbers.
+// Numbers are tried first, so with
+//
+//	type Level int
+//
+//	configloader.RegisterConverter(Level(0), configloader.EnumConverter(Level(0), map[string]int{
+//		"DEBUG": 1,
+//		"INFO":  2,
+//	}))
+//
+// both CONFIG_LEVEL=2 and CONFIG_LEVEL=INFO set Level(2). Names are
+// matched ignoring case. Other values are an error listing the valid
+// ones.
+func EnumConverter(value interface{}, names map[string]int) Converter {
+	typ := reflect.TypeOf(value)
+	return func(rawValue string) (interface{}, error) {
+		if number, err := strconv.Atoi(rawValue); err == nil {
+			for _, known := range names {
+				if known == number {
+					return reflect.ValueOf(number).Convert(typ).Interface(), nil
+				}
+			}
+		} else if number, ok := lookupEnumName(names, rawValue); ok {
+			return reflect.ValueOf(number).Convert(typ).Interface(), nil
+		}
+		return nil, fmt.Errorf("valid values are %s", formatEnumNames(names))
+	}
+}
+
+func lookupEnumName(names map[string]int, rawValue string) (int, bool) {
+	if number, ok := names[rawValue]; ok {
+		return number, true
+	}
+	for name, number := range names {
+		if strings.EqualFold(name, rawValue) {
+			return number, true
+		}
+	}
+	return 0, false
+}
+
+// formatEnumNames lists names with their numbers, like "DEBUG (1),
+// INFO (2)", sorted by number.
+func formatEnumNames(names map[string]int) string {
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if names[sorted[i]] != names[sorted[j]] {
+			return names[sorted[i]] < names[sorted[j]]
+		}
+		return sorted[i] < sorted[j]
+	})
+	for i, name := range sorted {
+		sorted[i] = fmt.Sprintf("%s (%d)", name, names[name])
+	}
+	return strings.Join(sorted, ", ")
+}
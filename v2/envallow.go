@@ -0,0 +1,36 @@
+package configloader
+
+import "os"
+
+// WithAllowedVars makes the hook read only the env vars in names,
+// ignoring every other one even if it matches a field, so a var
+// injected into the environment can't change the config. Names are
+// exact, so list derived vars like CONFIG_PASSWORD_FILE (with
+// WithFileSecrets) or CONFIG_HOSTS_0 too if they are used. Combine it
+// with WithRequiredPrefix to also skip listed vars without the prefix.
+// Calling it again adds to the previous names.
+func (hook EnvHook) WithAllowedVars(names ...string) EnvHook {
+	allowed := make(map[string]bool, len(hook.allowed)+len(names))
+	for name := range hook.allowed {
+		allowed[name] = true
+	}
+	for _, name := range names {
+		allowed[name] = true
+	}
+	hook.allowed = allowed
+	return hook
+}
+
+// isAllowed reports whether the hook may read the env var name.
+func (hook EnvHook) isAllowed(name string) bool {
+	return hook.allowed == nil || hook.allowed[name]
+}
+
+// lookupEnv works like os.LookupEnv, but for vars the hook may not
+// read it reports they are not set.
+func (hook EnvHook) lookupEnv(name string) (string, bool) {
+	if !hook.isAllowed(name) {
+		return "", false
+	}
+	return os.LookupEnv(name)
+}
@@ -0,0 +1,113 @@
+package configloader
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// capturePattern is a configCapture tag value split around its *.
+type capturePattern struct {
+	before string
+	after  string
+}
+
+func parseCapturePattern(pattern string) (capturePattern, error) {
+	if strings.Count(pattern, "*") != 1 {
+		return capturePattern{}, fmt.Errorf("invalid configCapture '%s': expected exactly one *", pattern)
+	}
+	parts := strings.SplitN(pattern, "*", 2)
+	return capturePattern{before: strings.ToUpper(parts[0]), after: strings.ToUpper(parts[1])}, nil
+}
+
+// match returns the part of name matched by the *, if name starts
+// with prefix and matches the pattern.
+func (pattern capturePattern) match(prefix, name string) (string, bool) {
+	start := prefix + pattern.before
+	if !strings.HasPrefix(name, start) || !strings.HasSuffix(name, pattern.after) {
+		return "", false
+	}
+	if len(name) <= len(start)+len(pattern.after) {
+		return "", false
+	}
+	return name[len(start) : len(name)-len(pattern.after)], true
+}
+
+func (hook EnvHook) capturePrefixes() []string {
+	if hook.strict {
+		return hook.requiredPrefixes()
+	}
+	if hook.bare {
+		return []string{""}
+	}
+	return hook.prefixes
+}
+
+// capture fills a map field with every env var matching the field
+// configCapture pattern, like configCapture:"FLAG_*" collecting
+// CONFIG_FLAG_BETA=true as the key "beta". Keys are the part matched
+// by the *, in lowercase. The first hook prefix with any match wins.
+func (hook EnvHook) capture(loader *ConfigLoader, field currentField, tag string) error {
+	pattern, err := parseCapturePattern(tag)
+	if err != nil {
+		return fieldError(field, err)
+	}
+	for _, prefix := range hook.capturePrefixes() {
+		entries := make(map[string]string)
+		for _, env := range os.Environ() {
+			pair := strings.SplitN(env, "=", 2)
+			if key, ok := pattern.match(prefix, pair[0]); ok && hook.isAllowed(pair[0]) {
+				entries[strings.ToLower(key)] = pair[1]
+			}
+		}
+		if len(entries) > 0 {
+			return loader.setFieldEntries(field, entries)
+		}
+	}
+	return nil
+}
+
+// setFieldEntries sets a map field from a hook, entry by entry.
+func (loader *ConfigLoader) setFieldEntries(field currentField, entries map[string]string) error {
+	result := reflect.MakeMapWithSize(field.value.Type(), len(entries))
+	for key, rawValue := range entries {
+		if loader.trimSpace {
+			rawValue = strings.TrimSpace(rawValue)
+		}
+		keyValue := reflect.New(field.value.Type().Key()).Elem()
+		if err := setElem(keyValue, key, field.original.Tag); err != nil {
+			return loader.done(field, fieldError(field, err))
+		}
+		elem := reflect.New(field.value.Type().Elem()).Elem()
+		if err := setElem(elem, rawValue, field.original.Tag); err != nil {
+			return loader.done(field, fieldError(field, fmt.Errorf("key '%s': %w", key, err)))
+		}
+		result.SetMapIndex(keyValue, elem)
+	}
+	field.value.Set(result)
+	return loader.done(field, nil)
+}
+
+// isCaptured reports whether name is collected by a configCapture
+// field of target.
+func (hook EnvHook) isCaptured(loader *ConfigLoader, name string) bool {
+	captured := false
+	foreachField(loader.target, func(field currentField) error {
+		tag, ok := field.original.Tag.Lookup("configCapture")
+		if !ok {
+			return nil
+		}
+		pattern, err := parseCapturePattern(tag)
+		if err != nil {
+			return nil
+		}
+		for _, prefix := range hook.capturePrefixes() {
+			if _, ok := pattern.match(prefix, name); ok {
+				captured = true
+			}
+		}
+		return nil
+	})
+	return captured
+}
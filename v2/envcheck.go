@@ -0,0 +1,87 @@
+package configloader
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type unusedCheck int
+
+const (
+	unusedIgnore unusedCheck = iota
+	unusedWarn
+	unusedFail
+)
+
+// WithUnusedCheck makes the hook look for env vars starting with its
+// prefixes that do not map to any field, like CONFIG_PROT instead of
+// CONFIG_PORT. If strict is true they make loading fail; otherwise
+// they are logged and stored as warnings. It does nothing together
+// with WithoutPrefix, since any var could be unrelated.
+func (hook EnvHook) WithUnusedCheck(strict bool) EnvHook {
+	hook.unused = unusedWarn
+	if strict {
+		hook.unused = unusedFail
+	}
+	return hook
+}
+
+func (hook EnvHook) checkUnused(loader *ConfigLoader) error {
+	if hook.unused == unusedIgnore || hook.bare {
+		return nil
+	}
+	known := make(map[string]bool)
+	foreachField(loader.target, func(field currentField) error {
+		for _, name := range hook.envVarNames(loader.mapField(field)) {
+			known[name] = true
+		}
+		return nil
+	})
+	unused := make([]string, 0)
+	for _, env := range os.Environ() {
+		name := strings.SplitN(env, "=", 2)[0]
+		if hook.hasPrefix(name) && !isKnownEnvVar(known, name) && !hook.isCaptured(loader, name) && !hook.isStructMapEntry(loader, name) {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	sort.Strings(unused)
+	err := fmt.Errorf("env vars not matching any field: %s", strings.Join(unused, ", "))
+	if hook.unused == unusedFail {
+		return err
+	}
+	log.Println("Warning:", err)
+	loader.warnings = append(loader.warnings, err)
+	return nil
+}
+
+func (hook EnvHook) hasPrefix(name string) bool {
+	for _, prefix := range hook.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isKnownEnvVar also accepts the _FILE and indexed (_0, _1...)
+// variants of the known names.
+func isKnownEnvVar(known map[string]bool, name string) bool {
+	if known[name] || known[strings.TrimSuffix(name, "_FILE")] {
+		return true
+	}
+	separator := strings.LastIndex(name, "_")
+	if separator < 0 {
+		return false
+	}
+	if _, err := strconv.Atoi(name[separator+1:]); err != nil {
+		return false
+	}
+	return known[name[:separator]]
+}
@@ -0,0 +1,134 @@
+package configloader
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// isStructMap reports whether typ is a map of structs, like
+// map[string]ServiceConfig, which the env hook fills entry by entry.
+func isStructMap(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String &&
+		typ.Elem().Kind() == reflect.Struct && !isValueStruct(typ.Elem())
+}
+
+// loadStructMap fills a map of structs from env vars named like
+// CONFIG_SERVICES_<KEY>_PORT, where CONFIG_SERVICES is the var of the
+// map field and PORT the one of a field of the struct, without prefix.
+// Keys are discovered from the vars that are set, and stored in
+// lowercase. Entries already in the map are updated, not replaced.
+func (hook EnvHook) loadStructMap(loader *ConfigLoader, field currentField) error {
+	for _, name := range hook.envVarNames(field) {
+		entries := hook.findStructMapEntries(name+"_", field.value.Type().Elem())
+		if len(entries) == 0 {
+			continue
+		}
+		if field.value.IsNil() {
+			field.value.Set(reflect.MakeMap(field.value.Type()))
+		}
+		for key, values := range entries {
+			if err := setStructMapEntry(loader, field, key, values); err != nil {
+				return loader.done(field, err)
+			}
+		}
+		return loader.done(field, nil)
+	}
+	return nil
+}
+
+// findStructMapEntries groups the vars starting with base by key,
+// mapping each key to the values of the struct fields, by field name.
+func (hook EnvHook) findStructMapEntries(base string, typ reflect.Type) map[string]map[string]string {
+	suffixes := getStructMapSuffixes(typ)
+	entries := make(map[string]map[string]string)
+	for _, env := range os.Environ() {
+		pair := strings.SplitN(env, "=", 2)
+		if !strings.HasPrefix(pair[0], base) || !hook.isAllowed(pair[0]) {
+			continue
+		}
+		key, fieldName, ok := matchStructMapVar(pair[0][len(base):], suffixes)
+		if !ok {
+			continue
+		}
+		if entries[key] == nil {
+			entries[key] = make(map[string]string)
+		}
+		entries[key][fieldName] = pair[1]
+	}
+	return entries
+}
+
+// matchStructMapVar splits the end of a var, like API_PORT, into the
+// map key and the struct field it sets. The longest suffix wins.
+func matchStructMapVar(rest string, suffixes map[string]string) (string, string, bool) {
+	fieldName, suffix := "", ""
+	for name, current := range suffixes {
+		if strings.HasSuffix(rest, current) && len(rest) > len(current) && len(current) > len(suffix) {
+			fieldName, suffix = name, current
+		}
+	}
+	if len(suffix) == 0 {
+		return "", "", false
+	}
+	return strings.ToLower(rest[:len(rest)-len(suffix)]), fieldName, true
+}
+
+// getStructMapSuffixes maps the name of each field of typ to the end
+// of its env var, like _PORT.
+func getStructMapSuffixes(typ reflect.Type) map[string]string {
+	suffixes := make(map[string]string)
+	for _, meta := range getTypeFields(typ) {
+		suffixes[meta.name] = "_" + strings.ToUpper(meta.name)
+	}
+	return suffixes
+}
+
+func setStructMapEntry(loader *ConfigLoader, field currentField, key string, values map[string]string) error {
+	keyValue := reflect.ValueOf(key).Convert(field.value.Type().Key())
+	entry := reflect.New(field.value.Type().Elem())
+	if current := field.value.MapIndex(keyValue); current.IsValid() {
+		entry.Elem().Set(current)
+	}
+	err := foreachField(entry.Interface(), func(sub currentField) error {
+		rawValue, ok := values[sub.name]
+		if !ok {
+			return nil
+		}
+		if loader.trimSpace {
+			rawValue = strings.TrimSpace(rawValue)
+		}
+		sub.name = fmt.Sprintf("%s.%s.%s", field.name, key, sub.name)
+		// Entries of a secret map are secrets too.
+		sub.secret = sub.secret || isSecret(field)
+		return setField(sub, rawValue)
+	})
+	if err != nil {
+		return err
+	}
+	field.value.SetMapIndex(keyValue, entry.Elem())
+	return nil
+}
+
+// isStructMapEntry reports whether name sets an entry of a map of
+// structs of target.
+func (hook EnvHook) isStructMapEntry(loader *ConfigLoader, name string) bool {
+	found := false
+	foreachField(loader.target, func(field currentField) error {
+		if !isStructMap(field.value.Type()) {
+			return nil
+		}
+		suffixes := getStructMapSuffixes(field.value.Type().Elem())
+		for _, base := range hook.envVarNames(loader.mapField(field)) {
+			if !strings.HasPrefix(name, base+"_") {
+				continue
+			}
+			if _, _, ok := matchStructMapVar(name[len(base)+1:], suffixes); ok {
+				found = true
+			}
+		}
+		return nil
+	})
+	return found
+}
@@ -0,0 +1,47 @@
+package configloader
+
+import (
+	"strings"
+	"testing"
+)
+
+type envMapService struct {
+	Port int
+}
+
+func TestStructMapEntriesFromEnv(t *testing.T) {
+	setTestEnv(t, "CONFIG_SERVICES_USER_API_PORT", "8080")
+	var config struct {
+		Services map[string]envMapService
+	}
+	if _, err := NewConfigLoaderFor(&config).AddHook(CreateEnvHook()).SafeRetrieve(); err != nil {
+		t.Fatal(err)
+	}
+	if config.Services["user_api"].Port != 8080 {
+		t.Errorf("unexpected services %+v", config.Services)
+	}
+}
+
+func TestSecretStructMapEntriesAreRedacted(t *testing.T) {
+	setTestEnv(t, "CONFIG_TOKENS_API_PORT", "hunter2")
+	setTestEnv(t, "CONFIG_VAULTS_API_PORT", "hunter2")
+	var tagged struct {
+		Tokens map[string]envMapService `configSecret:"true"`
+	}
+	var listed struct {
+		Vaults map[string]envMapService
+	}
+	loaders := []*ConfigLoader{
+		NewConfigLoaderFor(&tagged),
+		NewConfigLoaderFor(&listed).WithSecrets("Vaults"),
+	}
+	for _, loader := range loaders {
+		_, err := loader.AddHook(CreateEnvHook()).SafeRetrieve()
+		if err == nil || !strings.Contains(err.Error(), "value [redacted] is not a valid int") {
+			t.Errorf("expected a redacted error, got %v", err)
+		}
+		if err != nil && strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("error leaks the secret: %v", err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package configloader
+
+import "testing"
+
+type envPrefixPrimary struct {
+	Host string
+}
+
+type envPrefixConfig struct {
+	Name  string
+	Redis struct {
+		Host string
+	} `configPrefix:"redis"`
+	Database struct {
+		Primary envPrefixPrimary `configPrefix:"primary"`
+	} `configPrefix:"db"`
+}
+
+// Without a delimiter only the innermost configPrefix is used, as
+// before prefixes could be composed.
+func TestEnvPrefixComposition(t *testing.T) {
+	cases := []struct {
+		loaderPrefix string
+		hook         EnvHook
+		vars         [3]string
+	}{
+		{"", CreateEnvHook(), [3]string{"CONFIG_NAME", "CONFIG_REDISHOST", "CONFIG_PRIMARYHOST"}},
+		{"", CreateEnvHook().WithDelimiter("_"), [3]string{"CONFIG_NAME", "CONFIG_REDIS_HOST", "CONFIG_DB_PRIMARY_HOST"}},
+		{"TENANT1_", CreateEnvHook(), [3]string{"TENANT1_NAME", "TENANT1_REDISHOST", "TENANT1_PRIMARYHOST"}},
+		{"TENANT1_", CreateEnvHook().WithDelimiter("_"), [3]string{"TENANT1_NAME", "TENANT1_REDIS_HOST", "TENANT1_DB_PRIMARY_HOST"}},
+		{"TENANT1_", CreateEnvHookWithPrefixes("APP_").WithDelimiter("_"), [3]string{"APP_NAME", "APP_REDIS_HOST", "APP_DB_PRIMARY_HOST"}},
+		{"", CreateEnvHook().WithDelimiter("__"), [3]string{"CONFIG_NAME", "CONFIG_REDIS__HOST", "CONFIG_DB__PRIMARY__HOST"}},
+	}
+	for _, test := range cases {
+		setTestEnv(t, test.vars[0], "name")
+		setTestEnv(t, test.vars[1], "redis")
+		setTestEnv(t, test.vars[2], "primary")
+		var config envPrefixConfig
+		loader := NewConfigLoaderFor(&config)
+		if len(test.loaderPrefix) > 0 {
+			loader.WithEnvPrefix(test.loaderPrefix)
+		}
+		if _, err := loader.AddHook(test.hook).SafeRetrieve(); err != nil {
+			t.Fatal(err)
+		}
+		if config.Name != "name" || config.Redis.Host != "redis" || config.Database.Primary.Host != "primary" {
+			t.Errorf("%v: unexpected config %+v", test.vars, config)
+		}
+	}
+}
+
+func TestEnvPrefixIgnoresOtherNamespaces(t *testing.T) {
+	setTestEnv(t, "CONFIG_NAME", "default")
+	setTestEnv(t, "TENANT1_NAME", "tenant1")
+	var config envPrefixConfig
+	_, err := NewConfigLoaderFor(&config).
+		WithEnvPrefix("TENANT2_").
+		AddHook(CreateEnvHook()).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "" {
+		t.Errorf("expected Name to stay empty, got %q", config.Name)
+	}
+}
@@ -0,0 +1,43 @@
+package configloader
+
+import (
+	"errors"
+	"strings"
+)
+
+// WithRequiredPrefix makes the hook ignore every env var that does not
+// start with one of its prefixes, so vars like PATH or HOME can never
+// set fields named Path or Home on a shared host. configEnv tags and
+// WithoutPrefix names lacking the prefix are skipped too. Running the
+// hook without a non empty prefix is an error.
+func (hook EnvHook) WithRequiredPrefix() EnvHook {
+	hook.strict = true
+	return hook
+}
+
+var errEnvPrefixRequired = errors.New("env hook requires a prefix, but it has none")
+
+// requiredPrefixes returns the non empty prefixes of the hook.
+func (hook EnvHook) requiredPrefixes() []string {
+	prefixes := make([]string, 0, len(hook.prefixes))
+	for _, prefix := range hook.prefixes {
+		if len(prefix) > 0 {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// withPrefix drops the names which do not start with a prefix.
+func (hook EnvHook) withPrefix(names []string) []string {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, prefix := range hook.requiredPrefixes() {
+			if strings.HasPrefix(name, prefix) {
+				result = append(result, name)
+				break
+			}
+		}
+	}
+	return result
+}
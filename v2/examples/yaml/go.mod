@@ -0,0 +1,10 @@
+module github.com/deltegui/configloader/v2/examples/yaml
+
+go 1.16
+
+require (
+	github.com/deltegui/configloader/v2 v2.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/deltegui/configloader/v2 => ../..
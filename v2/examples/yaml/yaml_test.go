@@ -0,0 +1,70 @@
+package yaml_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deltegui/configloader/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type service struct {
+	Host    string `yaml:"host"`
+	Port    int    `yaml:"port"`
+	Retries int    `yaml:"retries"`
+}
+
+type config struct {
+	Name     string             `yaml:"name"`
+	Services map[string]service `yaml:"services"`
+}
+
+const document = `
+base: &base
+  host: localhost
+  retries: 3
+
+services:
+  api:
+    <<: *base
+    port: 8080
+  worker:
+    <<: *base
+    host: worker.local
+    retries: 5
+`
+
+func decodeYAML(reader io.Reader, target interface{}) error {
+	return yaml.NewDecoder(reader).Decode(target)
+}
+
+func TestDecoderHookResolvesAnchorsAndMerges(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte(document), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config{Name: "default"}
+	_, err := configloader.NewConfigLoaderFor(cfg).
+		AddHook(configloader.CreateDecoderHook(file, decodeYAML)).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "default" {
+		t.Errorf("Name = %q, want the value from before the hook", cfg.Name)
+	}
+	want := map[string]service{
+		"api":    {Host: "localhost", Port: 8080, Retries: 3},
+		"worker": {Host: "worker.local", Port: 0, Retries: 5},
+	}
+	if len(cfg.Services) != len(want) {
+		t.Fatalf("Services = %+v, want %+v", cfg.Services, want)
+	}
+	for name, expected := range want {
+		if got := cfg.Services[name]; got != expected {
+			t.Errorf("Services[%q] = %+v, want %+v", name, got, expected)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package configloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FlatFileHook will load data from a flat JSON file, where
+// nested fields are written as dotted keys. For example
+// {"redis.Name": "cache"} sets Name inside a struct field
+// with configPrefix "redis" (or named Redis, if it has no prefix).
+// Keys are matched ignoring case, as encoding/json does.
+//
+// Nested objects are also accepted, so a file can mix both
+// styles. If the same field appears both as a dotted key and
+// inside a nested object, the dotted key wins.
+type FlatFileHook struct {
+	file        string
+	strictTypes bool
+}
+
+// CreateFlatFileHook passing flat JSON file.
+func CreateFlatFileHook(file string) FlatFileHook {
+	return FlatFileHook{file: file}
+}
+
+type flatEntry struct {
+	value interface{}
+	depth int
+}
+
+func (hook FlatFileHook) source() string {
+	return "file"
+}
+
+func (hook FlatFileHook) run(loader *ConfigLoader) error {
+	document := make(map[string]interface{})
+	if err := decodeJSONFile(hook.file, &document); err != nil {
+		return err
+	}
+	entries := make(map[string]flatEntry)
+	flattenJSON("", document, 0, entries)
+	return loader.foreachField(func(field currentField) error {
+		entry, ok := entries[strings.ToLower(strings.Join(field.path, "."))]
+		if !ok || entry.value == nil {
+			return nil
+		}
+		if hook.strictTypes {
+			if err := checkJSONFieldType(field.value.Type(), entry.value); err != nil {
+				return loader.done(field, fieldError(field, err))
+			}
+		}
+		value, err := formatJSONField(field, entry.value)
+		if err != nil {
+			return loader.done(field, fieldError(field, err))
+		}
+		return loader.setField(field, value)
+	})
+}
+
+// decodeJSONFile decodes a JSON file into document, keeping
+// numbers as json.Number so they are not rounded.
+func decodeJSONFile(path string, document interface{}) error {
+	file, err := os.OpenFile(path, os.O_RDONLY, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error while reading config file: %w", err)
+	}
+	defer file.Close()
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	if err := decoder.Decode(document); err != nil {
+		return fmt.Errorf("error while decoding config file: %w", err)
+	}
+	return nil
+}
+
+// flattenJSON stores every leaf of document in entries, keyed by
+// its lowercased dotted path. When two keys resolve to the same
+// path, the one written with less nesting is kept.
+func flattenJSON(prefix string, document map[string]interface{}, depth int, entries map[string]flatEntry) {
+	for key, value := range document {
+		path := strings.ToLower(key)
+		if len(prefix) > 0 {
+			path = fmt.Sprintf("%s.%s", prefix, path)
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenJSON(path, nested, depth+1, entries)
+			continue
+		}
+		if current, ok := entries[path]; ok && current.depth <= depth {
+			continue
+		}
+		entries[path] = flatEntry{value: value, depth: depth}
+	}
+}
+
+// formatJSONField formats a decoded JSON value so setField can
+// read it. Arrays are joined using the field configSeparator.
+func formatJSONField(field currentField, value interface{}) (string, error) {
+	typ := field.value.Type()
+	if list, ok := value.([]interface{}); ok {
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			typ = typ.Elem()
+		}
+		return formatJSONList(list, typ, getTagOr(field.original.Tag, "configSeparator", defaultSeparator))
+	}
+	return formatJSONValue(value, typ)
+}
+
+// formatJSONValue formats value for a field of type typ. Numbers
+// for integer fields are written without exponent or decimals, like
+// 1000 for 1e3, so they parse as integers; numbers with a fraction
+// are an error instead of being truncated.
+func formatJSONValue(value interface{}, typ reflect.Type) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		if isIntegerType(typ) {
+			return formatJSONInteger(v.String(), typ)
+		}
+		return v.String(), nil
+	case float64:
+		if isIntegerType(typ) {
+			if math.IsInf(v, 0) || math.IsNaN(v) || v != math.Trunc(v) {
+				return "", fmt.Errorf("value '%v' is not a valid integer", v)
+			}
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// maxJSONExponent is far more than the 20 digits of the widest
+// integer field, and small enough to expand cheaply.
+const maxJSONExponent = 1000
+
+// formatJSONInteger writes a JSON number as a plain integer. It is
+// parsed as an exact fraction, so large values keep every digit.
+// Huge exponents are rejected first, since no integer field could
+// hold them anyway.
+func formatJSONInteger(number string, typ reflect.Type) (string, error) {
+	if at := strings.IndexAny(number, "eE"); at >= 0 {
+		if exponent, err := strconv.Atoi(number[at+1:]); err != nil || exponent > maxJSONExponent || exponent < -maxJSONExponent {
+			return "", fmt.Errorf("value '%s' overflows %s", number, typ)
+		}
+	}
+	rat, ok := new(big.Rat).SetString(number)
+	if !ok || !rat.IsInt() {
+		return "", fmt.Errorf("value '%s' is not a valid integer", number)
+	}
+	return rat.Num().String(), nil
+}
+
+// isIntegerType reports whether fields of type typ hold integers.
+// Durations and types with a converter are left out, since they
+// read numbers their own way.
+func isIntegerType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if _, ok := lookupConverter(typ); ok || typ == durationType {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func formatJSONList(list []interface{}, typ reflect.Type, separator string) (string, error) {
+	items := make([]string, 0, len(list))
+	for i, item := range list {
+		formatted, err := formatJSONValue(item, typ)
+		if err != nil {
+			return "", fmt.Errorf("item %d: %w", i, err)
+		}
+		items = append(items, formatted)
+	}
+	return strings.Join(items, separator), nil
+}
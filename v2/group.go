@@ -0,0 +1,109 @@
+package configloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// groupsCache maps each struct type to whether it has configGroup
+// tags anywhere, so targets without them skip rewriting JSON.
+var groupsCache sync.Map
+
+// hasGroups reports whether typ, or any struct nested in it, has a
+// field with a configGroup tag.
+func hasGroups(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || isValueStruct(typ) {
+		return false
+	}
+	if cached, ok := groupsCache.Load(typ); ok {
+		return cached.(bool)
+	}
+	found := hasGroupsIn(typ, map[reflect.Type]bool{})
+	groupsCache.Store(typ, found)
+	return found
+}
+
+// hasGroupsIn does the work of hasGroups. visited holds the structs
+// already checked, so recursive types end.
+func hasGroupsIn(typ reflect.Type, visited map[reflect.Type]bool) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || isValueStruct(typ) || visited[typ] {
+		return false
+	}
+	visited[typ] = true
+	for i := 0; i < typ.NumField(); i++ {
+		field, _ := expandConfigTag(typ.Field(i))
+		if _, tagged := field.Tag.Lookup("configGroup"); tagged || hasGroupsIn(field.Type, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// getJSONKey returns the key of field in JSON documents: its
+// configGroup if it has one, or else its JSON name.
+func getJSONKey(field reflect.StructField) string {
+	if group := field.Tag.Get("configGroup"); len(group) > 0 {
+		return group
+	}
+	return getJSONName(field)
+}
+
+// regroupJSON renames the configGroup keys of a JSON document to the
+// JSON names of their fields, so encoding/json can decode it.
+func regroupJSON(target interface{}, data []byte) ([]byte, error) {
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	document = regroupJSONValue(reflect.TypeOf(target), document)
+	return json.Marshal(document)
+}
+
+func regroupJSONValue(typ reflect.Type, value interface{}) interface{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok || typ.Kind() != reflect.Struct || isValueStruct(typ) {
+		return value
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field, _ := expandConfigTag(typ.Field(i))
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		key := findJSONKey(object, getJSONKey(field))
+		if len(key) == 0 {
+			continue
+		}
+		nested := regroupJSONValue(field.Type, object[key])
+		delete(object, key)
+		object[getJSONName(field)] = nested
+	}
+	return object
+}
+
+// findJSONKey returns the key of object matching name, preferring an
+// exact match but ignoring case otherwise, like encoding/json does.
+func findJSONKey(object map[string]interface{}, name string) string {
+	if _, ok := object[name]; ok {
+		return name
+	}
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return ""
+}
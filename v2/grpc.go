@@ -0,0 +1,87 @@
+package configloader
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GRPCFetcher calls your config service for a key, usually with
+// a generated gRPC client, and returns its response message.
+type GRPCFetcher func(ctx context.Context, key string) (interface{}, error)
+
+// GRPCAdapter reads a response message from a GRPCFetcher and
+// calls set with each field name (as used by params) and its value.
+type GRPCAdapter func(response interface{}, set func(name, value string))
+
+// GRPCHook loads data from a gRPC config service. The hook does not
+// depend on gRPC itself: you provide the call and the mapping from the
+// response message to fields, so any proto shape works.
+type GRPCHook struct {
+	key     string
+	fetch   GRPCFetcher
+	adapt   GRPCAdapter
+	ctx     context.Context
+	timeout time.Duration
+}
+
+// CreateGRPCHook creates a hook which fetches key with fetch and maps
+// the response with adapt. The call gets a 30 seconds deadline unless
+// you change it with WithTimeout.
+//
+//	hook := configloader.CreateGRPCHook("billing", func(ctx context.Context, key string) (interface{}, error) {
+//		return client.GetConfig(ctx, &pb.GetConfigRequest{Key: key})
+//	}, func(response interface{}, set func(name, value string)) {
+//		for _, entry := range response.(*pb.GetConfigResponse).Entries {
+//			set(entry.Name, entry.Value)
+//		}
+//	})
+func CreateGRPCHook(key string, fetch GRPCFetcher, adapt GRPCAdapter) GRPCHook {
+	return GRPCHook{
+		key:     key,
+		fetch:   fetch,
+		adapt:   adapt,
+		ctx:     context.Background(),
+		timeout: defaultHTTPTimeout,
+	}
+}
+
+// WithContext sets the parent context of the call.
+func (hook GRPCHook) WithContext(ctx context.Context) GRPCHook {
+	hook.ctx = ctx
+	return hook
+}
+
+// WithTimeout changes the deadline of the call. Zero means no deadline
+// other than the one of the parent context.
+func (hook GRPCHook) WithTimeout(timeout time.Duration) GRPCHook {
+	hook.timeout = timeout
+	return hook
+}
+
+func (hook GRPCHook) source() string {
+	return "grpc"
+}
+
+func (hook GRPCHook) run(loader *ConfigLoader) error {
+	ctx := hook.ctx
+	if hook.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hook.timeout)
+		defer cancel()
+	}
+	response, err := hook.fetch(ctx, hook.key)
+	if err != nil {
+		return fmt.Errorf("error while fetching config %s: %w", hook.key, err)
+	}
+	values := make(map[string]string)
+	hook.adapt(response, func(name, value string) {
+		values[name] = value
+	})
+	return loader.foreachField(func(field currentField) error {
+		if value, ok := values[field.name]; ok {
+			return loader.setField(field, value)
+		}
+		return nil
+	})
+}
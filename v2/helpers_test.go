@@ -0,0 +1,32 @@
+package configloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestFile writes content to a file named name in a temporary
+// directory removed after the test, returning its path.
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// setTestEnv sets an env var for the length of the test.
+func setTestEnv(t *testing.T, name, value string) {
+	t.Helper()
+	previous, ok := os.LookupEnv(name)
+	os.Setenv(name, value)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(name, previous)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
+}
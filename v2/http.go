@@ -0,0 +1,69 @@
+package configloader
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+// HTTPHook will load data from a JSON document served by a URL.
+type HTTPHook struct {
+	url    string
+	client *http.Client
+}
+
+// CreateHTTPHook creates a hook which fetches url with a GET
+// request and decodes the JSON response, like CreateFileHook.
+func CreateHTTPHook(url string) HTTPHook {
+	return HTTPHook{
+		url:    url,
+		client: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+// WithClient changes the http.Client used to fetch the URL.
+func (hook HTTPHook) WithClient(client *http.Client) HTTPHook {
+	hook.client = client
+	return hook
+}
+
+// CreateHTTPHooksLayered creates a hook for each url. Add them with
+// AddHooks, and they are fetched in order and merged into the target,
+// later URLs winning for each field they set:
+//
+//	loader.AddHooks(configloader.CreateHTTPHooksLayered(global, regional, service)...)
+//
+// Wrap a layer with Optional if its failure should be skipped.
+func CreateHTTPHooksLayered(urls ...string) []Hook {
+	hooks := make([]Hook, 0, len(urls))
+	for _, url := range urls {
+		hooks = append(hooks, CreateHTTPHook(url))
+	}
+	return hooks
+}
+
+func (hook HTTPHook) source() string {
+	return "http"
+}
+
+func (hook HTTPHook) run(loader *ConfigLoader) error {
+	response, err := hook.client.Get(hook.url)
+	if err != nil {
+		return fmt.Errorf("error while fetching config from %s: %w", hook.url, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("error while fetching config from %s: %s", hook.url, response.Status)
+	}
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("error while fetching config from %s: %w", hook.url, err)
+	}
+	if err := loader.decodeJSON(data, false); err != nil {
+		return fmt.Errorf("error while decoding config from %s: %w", hook.url, err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package configloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// hasDurations reports whether typ, or any struct, list or map in it,
+// holds a time.Duration.
+func hasDurations(typ reflect.Type) bool {
+	return hasDurationsIn(typ, map[reflect.Type]bool{})
+}
+
+// hasDurationsIn does the work of hasDurations. visited holds the
+// types already checked, so recursive types end.
+func hasDurationsIn(typ reflect.Type, visited map[reflect.Type]bool) bool {
+	if typ == durationType {
+		return true
+	}
+	if visited[typ] {
+		return false
+	}
+	visited[typ] = true
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return hasDurationsIn(typ.Elem(), visited)
+	case reflect.Struct:
+		if isValueStruct(typ) {
+			return false
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			if hasDurationsIn(typ.Field(i).Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseJSONDurations rewrites data so durations written as strings,
+// like "30s" or "30000000000", or as numbers in the configDurationUnit
+// of the field, become numbers of nanoseconds, which is what
+// encoding/json reads into a time.Duration. Values are parsed like in
+// setDuration, so fractions must give whole nanoseconds, and invalid
+// values are an error.
+func parseJSONDurations(target interface{}, data []byte) ([]byte, error) {
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	var err error
+	document = rewriteJSONValue(reflect.TypeOf(target).Elem(), "", document, func(typ reflect.Type, tag reflect.StructTag, value interface{}) interface{} {
+		if typ != durationType || value == nil || err != nil {
+			return value
+		}
+		var duration time.Duration
+		duration, err = parseJSONDuration(value, tag)
+		return json.Number(strconv.FormatInt(int64(duration), 10))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(document)
+}
+
+// parseJSONDuration reads a duration from a JSON string or number.
+// Plain numbers are in the unit of the configDurationUnit tag, as
+// with the other sources.
+func parseJSONDuration(value interface{}, tag reflect.StructTag) (time.Duration, error) {
+	unit, err := durationUnit(tag)
+	if err != nil {
+		return 0, err
+	}
+	switch current := value.(type) {
+	case string:
+		return parseDuration(current, unit)
+	case json.Number:
+		return parseDuration(current.String(), unit)
+	}
+	return 0, fmt.Errorf("value %v is not a valid duration", value)
+}
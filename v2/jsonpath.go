@@ -0,0 +1,93 @@
+package configloader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPathHook loads fields from any place of a JSON file, using
+// the JSON pointer (RFC 6901) in their configJSONPath tag:
+//
+//	Port int `configJSONPath:"/services/db/port"`
+//
+// Fields without the tag are ignored. If a path does not exist in
+// the file, the field is left untouched. Paths pointing to an object
+// are an error, because only values can be loaded into fields.
+type JSONPathHook struct {
+	file        string
+	strictTypes bool
+}
+
+// CreateJSONPathHook passing JSON file.
+func CreateJSONPathHook(file string) JSONPathHook {
+	return JSONPathHook{file: file}
+}
+
+func (hook JSONPathHook) source() string {
+	return "file"
+}
+
+func (hook JSONPathHook) run(loader *ConfigLoader) error {
+	var document interface{}
+	if err := decodeJSONFile(hook.file, &document); err != nil {
+		return err
+	}
+	return loader.foreachField(func(field currentField) error {
+		pointer, ok := field.original.Tag.Lookup("configJSONPath")
+		if !ok {
+			return nil
+		}
+		value, found, err := resolveJSONPointer(document, pointer)
+		if err != nil {
+			return fmt.Errorf("field '%s': %w", field.name, err)
+		}
+		if !found || value == nil {
+			return nil
+		}
+		if _, isObject := value.(map[string]interface{}); isObject {
+			return fmt.Errorf("field '%s': JSON path '%s' points to an object", field.name, pointer)
+		}
+		if hook.strictTypes {
+			if err := checkJSONFieldType(field.value.Type(), value); err != nil {
+				return loader.done(field, fieldError(field, err))
+			}
+		}
+		formatted, err := formatJSONField(field, value)
+		if err != nil {
+			return loader.done(field, fieldError(field, err))
+		}
+		return loader.setField(field, formatted)
+	})
+}
+
+// resolveJSONPointer returns the value at pointer, and whether it exists.
+func resolveJSONPointer(document interface{}, pointer string) (interface{}, bool, error) {
+	if len(pointer) == 0 {
+		return document, true, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false, fmt.Errorf("invalid JSON path '%s': it must start with /", pointer)
+	}
+	current := document
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, ok := node[token]
+			if !ok {
+				return nil, false, nil
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false, nil
+			}
+			current = node[index]
+		default:
+			return nil, false, nil
+		}
+	}
+	return current, true, nil
+}
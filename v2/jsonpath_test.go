@@ -0,0 +1,39 @@
+package configloader
+
+import "testing"
+
+func TestJSONPathLoadsValues(t *testing.T) {
+	file := writeTestFile(t, "config.json", `{"server": {"ports": [80, 443]}}`)
+	var config struct {
+		Port int `configJSONPath:"/server/ports/1"`
+	}
+	if _, err := NewConfigLoaderFor(&config).AddHook(CreateJSONPathHook(file)).SafeRetrieve(); err != nil {
+		t.Fatal(err)
+	}
+	if config.Port != 443 {
+		t.Errorf("expected 443, got %d", config.Port)
+	}
+}
+
+func TestJSONPathErrorsQuoteTheField(t *testing.T) {
+	file := writeTestFile(t, "config.json", `{"server": {"port": 80}}`)
+	var relative struct {
+		Port int `configJSONPath:"server/port"`
+	}
+	var object struct {
+		Port int `configJSONPath:"/server"`
+	}
+	cases := []struct {
+		target   interface{}
+		expected string
+	}{
+		{&relative, "field 'Port': invalid JSON path 'server/port': it must start with /"},
+		{&object, "field 'Port': JSON path '/server' points to an object"},
+	}
+	for _, test := range cases {
+		_, err := NewConfigLoaderFor(test.target).AddHook(CreateJSONPathHook(file)).SafeRetrieve()
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("expected %q, got %v", test.expected, err)
+		}
+	}
+}
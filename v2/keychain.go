@@ -0,0 +1,35 @@
+package configloader
+
+import "fmt"
+
+// KeychainHook loads secrets from the OS keychain: the macOS
+// Keychain or, on Linux, the Secret Service (through secret-tool).
+type KeychainHook struct {
+	service string
+	mapping map[string]string
+}
+
+// CreateKeychainHook creates a hook which looks up the secrets of a
+// service. Mapping goes from field names (as used by params) to the
+// account name of each secret. A failed lookup is an error.
+func CreateKeychainHook(service string, mapping map[string]string) KeychainHook {
+	return KeychainHook{service: service, mapping: mapping}
+}
+
+func (hook KeychainHook) source() string {
+	return "keychain"
+}
+
+func (hook KeychainHook) run(loader *ConfigLoader) error {
+	return loader.foreachField(func(field currentField) error {
+		account, ok := hook.mapping[field.name]
+		if !ok {
+			return nil
+		}
+		secret, err := lookupKeychain(hook.service, account)
+		if err != nil {
+			return fmt.Errorf("error while reading keychain secret %s/%s for field %s: %w", hook.service, account, field.name, err)
+		}
+		return loader.setField(field, secret)
+	})
+}
@@ -0,0 +1,17 @@
+//go:build darwin
+// +build darwin
+
+package configloader
+
+import (
+	"os/exec"
+	"strings"
+)
+
+func lookupKeychain(service, account string) (string, error) {
+	output, err := exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
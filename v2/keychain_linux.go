@@ -0,0 +1,20 @@
+//go:build linux
+// +build linux
+
+package configloader
+
+import (
+	"errors"
+	"os/exec"
+)
+
+func lookupKeychain(service, account string) (string, error) {
+	output, err := exec.Command("secret-tool", "lookup", "service", service, "account", account).Output()
+	if err != nil {
+		return "", err
+	}
+	if len(output) == 0 {
+		return "", errors.New("secret not found")
+	}
+	return string(output), nil
+}
@@ -0,0 +1,10 @@
+//go:build !darwin && !linux
+// +build !darwin,!linux
+
+package configloader
+
+import "errors"
+
+func lookupKeychain(service, account string) (string, error) {
+	return "", errors.New("keychain is not supported on this platform")
+}
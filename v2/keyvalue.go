@@ -0,0 +1,92 @@
+package configloader
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NamingStrategy maps a field name (as used by params: configPrefix
+// followed by configName or the field name) to the key used by a source.
+type NamingStrategy func(name string) string
+
+// KeyValueOptions configures how a KeyValueHook reads its input.
+type KeyValueOptions struct {
+	// CommentPrefixes marks lines to skip. Defaults to "#".
+	CommentPrefixes []string
+	// Separator splits keys from values. Defaults to "=".
+	Separator string
+	// Naming maps field names to keys. Defaults to the loader naming
+	// strategy set with WithNamingStrategy, or the field name.
+	Naming NamingStrategy
+}
+
+// KeyValueHook loads data from a reader with a key and a value
+// per line, like dotenv or properties files.
+type KeyValueHook struct {
+	reader  io.Reader
+	options KeyValueOptions
+}
+
+// CreateKeyValueHook creates a hook which reads key value lines
+// from reader. Empty lines and comments are skipped, and keys and
+// values are trimmed. The reader is consumed when the hook runs.
+func CreateKeyValueHook(reader io.Reader, options KeyValueOptions) KeyValueHook {
+	if len(options.CommentPrefixes) == 0 {
+		options.CommentPrefixes = []string{"#"}
+	}
+	if len(options.Separator) == 0 {
+		options.Separator = "="
+	}
+	return KeyValueHook{reader: reader, options: options}
+}
+
+func (hook KeyValueHook) source() string {
+	return "keyvalue"
+}
+
+func (hook KeyValueHook) run(loader *ConfigLoader) error {
+	values, err := hook.read()
+	if err != nil {
+		return err
+	}
+	naming := loader.namingFor(hook.options.Naming)
+	return loader.foreachField(func(field currentField) error {
+		if value, ok := values[naming(field.name)]; ok {
+			return loader.setField(field, value)
+		}
+		return nil
+	})
+}
+
+func (hook KeyValueHook) read() (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(hook.reader)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || hook.isComment(text) {
+			continue
+		}
+		pair := strings.SplitN(text, hook.options.Separator, 2)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("invalid line %d: expected key%svalue", line, hook.options.Separator)
+		}
+		values[strings.TrimSpace(pair[0])] = strings.TrimSpace(pair[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error while reading key values: %w", err)
+	}
+	return values, nil
+}
+
+func (hook KeyValueHook) isComment(line string) bool {
+	for _, prefix := range hook.options.CommentPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package configloader
+
+import "fmt"
+
+// CreateFileHookNamed works like CreateFileHook, naming the file as a
+// layer, like "base" or "site", for stacks of several files. The name
+// shows in the errors of the hook, in its HookResult and in Origins,
+// so you can tell which file set each field.
+func CreateFileHookNamed(name, file string) ConfigFileHook {
+	return ConfigFileHook{file: file, name: name}
+}
+
+// layered is implemented by hooks which can be named as layers.
+type layered interface {
+	layer() string
+}
+
+func (hook ConfigFileHook) layer() string {
+	return hook.name
+}
+
+// hookLayer returns the layer name of hook, or "" if it has none.
+func hookLayer(hook Hook) string {
+	if optional, ok := hook.(OptionalHook); ok {
+		return hookLayer(optional.hook)
+	}
+	if required, ok := hook.(RequiredHook); ok {
+		return hookLayer(required.hook)
+	}
+	if named, ok := hook.(layered); ok {
+		return named.layer()
+	}
+	return ""
+}
+
+// layerError adds the layer name and file of a named file hook to
+// err, so failures in a stack of files say which one broke.
+func (hook ConfigFileHook) layerError(err error) error {
+	if err == nil || len(hook.name) == 0 {
+		return err
+	}
+	return fmt.Errorf("layer '%s' (%s): %w", hook.name, hook.file, err)
+}
+
+// Origins maps the name of each field set while loading to what set
+// its final value: the layer name of named file hooks, or else the
+// source of the hook, like "env". Fields no hook set are missing. It
+// follows the order of the hooks, so it ignores configPrecedence tags.
+func (loader *ConfigLoader) Origins() map[string]string {
+	origins := make(map[string]string)
+	for _, result := range loader.results {
+		origin := result.Source
+		if len(result.Layer) > 0 {
+			origin = result.Layer
+		}
+		for _, name := range result.Fields {
+			origins[name] = origin
+		}
+	}
+	return origins
+}
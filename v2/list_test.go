@@ -0,0 +1,183 @@
+package configloader
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListElementTypes(t *testing.T) {
+	cases := []struct {
+		raw      string
+		tag      reflect.StructTag
+		expected interface{}
+	}{
+		{"a, b ,c", "", []string{"a", "b", "c"}},
+		{"1,-2,3", "", []int{1, -2, 3}},
+		{"-128,127", "", []int8{-128, 127}},
+		{"1,2", "", []uint16{1, 2}},
+		{"1.5,-2e3", "", []float64{1.5, -2000}},
+		{"0.5", "", []float32{0.5}},
+		{"true,false", "", []bool{true, false}},
+		{"1;2;3", `configSeparator:";"`, []int64{1, 2, 3}},
+		{"a=1,b=2", "", map[string]int{"a": 1, "b": 2}},
+		{"1=0.5,2=1.5", "", map[int]float64{1: 0.5, 2: 1.5}},
+		{"on:true|off:false", `configSeparator:"|" configKeyValueSeparator:":"`, map[string]bool{"on": true, "off": false}},
+		{"x=a=b", "", map[string]string{"x": "a=b"}},
+	}
+	for _, test := range cases {
+		value := reflect.New(reflect.TypeOf(test.expected)).Elem()
+		if err := setValue(value, test.raw, test.tag); err != nil {
+			t.Errorf("%s into %T: %v", test.raw, test.expected, err)
+			continue
+		}
+		if !reflect.DeepEqual(value.Interface(), test.expected) {
+			t.Errorf("%s into %T: expected %v, got %v", test.raw, test.expected, test.expected, value.Interface())
+		}
+	}
+}
+
+func TestListElementErrors(t *testing.T) {
+	cases := []struct {
+		raw      string
+		target   interface{}
+		expected string
+	}{
+		{"1,x", []int{}, "item 1: value 'x' is not a valid integer"},
+		{"1,300", []uint8{}, "item 1: value '300' overflows uint8"},
+		{"a=1,b", map[string]int{}, "invalid map entry 'b': expected key=value"},
+		{"a=x", map[string]int{}, "value 'x' is not a valid integer"},
+		{"1,2", [][]int{}, "nested lists are not supported"},
+	}
+	for _, test := range cases {
+		value := reflect.New(reflect.TypeOf(test.target)).Elem()
+		if err := setValue(value, test.raw, ""); err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s into %T: expected %q, got %v", test.raw, test.target, test.expected, err)
+		}
+	}
+}
+
+func TestListFromHook(t *testing.T) {
+	var config struct {
+		Ports  []int
+		Limits map[string]uint
+	}
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(CreateTestHook(map[string]string{"Ports": "80,443", "Limits": "cpu=2,mem=512"})).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config.Ports, []int{80, 443}) || !reflect.DeepEqual(config.Limits, map[string]uint{"cpu": 2, "mem": 512}) {
+		t.Errorf("unexpected config %+v", config)
+	}
+}
+
+func TestArrayFromHook(t *testing.T) {
+	var config struct{ Coords [3]float64 }
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(CreateTestHook(map[string]string{"Coords": "1.0, 2.5,-3"})).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Coords != [3]float64{1, 2.5, -3} {
+		t.Errorf("unexpected coords %v", config.Coords)
+	}
+}
+
+func TestArrayLengthMismatch(t *testing.T) {
+	cases := []struct {
+		raw      string
+		expected string
+	}{
+		{"1.0,2.0", "field 'Coords': expected 3 items, got 2"},
+		{"1.0,2.0,3.0,4.0", "field 'Coords': expected 3 items, got 4"},
+		{"1.0,x,3.0", "field 'Coords': item 1: value 'x' is not a valid number"},
+	}
+	for _, test := range cases {
+		config := struct{ Coords [3]float64 }{Coords: [3]float64{7, 8, 9}}
+		_, err := NewConfigLoaderFor(&config).
+			AddHook(CreateTestHook(map[string]string{"Coords": test.raw})).
+			SafeRetrieve()
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("%s: expected %q, got %v", test.raw, test.expected, err)
+		}
+		if config.Coords != [3]float64{7, 8, 9} {
+			t.Errorf("%s: expected the array to be left alone, got %v", test.raw, config.Coords)
+		}
+	}
+}
+
+type timeListConfig struct {
+	Backoffs []time.Duration
+	Timeouts []time.Duration `configDurationUnit:"seconds"`
+	Windows  []time.Time
+	Days     []time.Time `configTimeFormat:"2006-01-02" configSeparator:";"`
+}
+
+func TestDurationAndTimeLists(t *testing.T) {
+	var config timeListConfig
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(CreateTestHook(map[string]string{
+			"Backoffs": "1s, 2s,4s",
+			"Timeouts": "5,1m",
+			"Windows":  "2024-01-02T03:04:05Z,2024-06-01T00:00:00+02:00",
+			"Days":     "2024-01-01;2024-12-31",
+		})).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config.Backoffs, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}) {
+		t.Errorf("unexpected backoffs %v", config.Backoffs)
+	}
+	if !reflect.DeepEqual(config.Timeouts, []time.Duration{5 * time.Second, time.Minute}) {
+		t.Errorf("unexpected timeouts %v", config.Timeouts)
+	}
+	windows := []time.Time{
+		time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		time.Date(2024, 5, 31, 22, 0, 0, 0, time.UTC),
+	}
+	if len(config.Windows) != 2 || !config.Windows[0].Equal(windows[0]) || !config.Windows[1].Equal(windows[1]) {
+		t.Errorf("unexpected windows %v", config.Windows)
+	}
+	days := []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)}
+	if !reflect.DeepEqual(config.Days, days) {
+		t.Errorf("unexpected days %v", config.Days)
+	}
+}
+
+func TestEmptyDurationAndTimeLists(t *testing.T) {
+	var config timeListConfig
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(CreateTestHook(map[string]string{"Backoffs": "", "Windows": " "})).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Backoffs == nil || len(config.Backoffs) != 0 || config.Windows == nil || len(config.Windows) != 0 {
+		t.Errorf("expected empty lists, got %v and %v", config.Backoffs, config.Windows)
+	}
+}
+
+func TestInvalidDurationAndTimeItems(t *testing.T) {
+	cases := []struct {
+		field    string
+		raw      string
+		expected string
+	}{
+		{"Backoffs", "1s,soon,4s", "field 'Backoffs': item 1: value 'soon' is not a valid duration"},
+		{"Days", "2024-01-01;tomorrow", "field 'Days': item 1: "},
+	}
+	for _, test := range cases {
+		var config timeListConfig
+		_, err := NewConfigLoaderFor(&config).
+			AddHook(CreateTestHook(map[string]string{test.field: test.raw})).
+			SafeRetrieve()
+		if err == nil || !strings.HasPrefix(err.Error(), test.expected) {
+			t.Errorf("%s: expected %q, got %v", test.raw, test.expected, err)
+		}
+	}
+}
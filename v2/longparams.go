@@ -0,0 +1,135 @@
+package configloader
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// LongParamsHook loads data from GNU style command line options:
+// --name=value, --name value and, for bool fields, just --name.
+type LongParamsHook struct{}
+
+// CreateLongParamsHook creates a hook which reads GNU style options
+// from the command line. Option names are the field names, as used
+// by ParamsHook, ignoring case. Fields with a configShort tag, like
+// configShort:"p", can also be set with -p value or -pvalue, and
+// bool short options can be combined: -vq sets both. Arguments after
+// a "--" terminator and arguments that are not options are ignored.
+// Unknown options are an error.
+func CreateLongParamsHook() LongParamsHook {
+	return LongParamsHook{}
+}
+
+func (hook LongParamsHook) source() string {
+	return "params"
+}
+
+type longOptions struct {
+	loader *ConfigLoader
+	long   map[string]currentField
+	short  map[string]currentField
+	extra  map[string]bool
+	args   []string
+}
+
+func (hook LongParamsHook) run(loader *ConfigLoader) error {
+	options := longOptions{
+		loader: loader,
+		long:   make(map[string]currentField),
+		short:  make(map[string]currentField),
+		extra:  make(map[string]bool),
+		args:   os.Args[1:],
+	}
+	err := loader.foreachField(func(field currentField) error {
+		options.long[strings.ToLower(field.name)] = field
+		if short := field.original.Tag.Get("configShort"); len(short) > 0 {
+			options.short[short] = field
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range loader.extraFlags {
+		options.extra[strings.ToLower(name)] = true
+	}
+	return options.parse()
+}
+
+func (options *longOptions) parse() error {
+	for len(options.args) > 0 {
+		arg := options.next()
+		var err error
+		switch {
+		case arg == "--":
+			return nil
+		case strings.HasPrefix(arg, "--"):
+			err = options.parseLong(strings.TrimPrefix(arg, "--"))
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			err = options.parseShort(strings.TrimPrefix(arg, "-"))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (options *longOptions) next() string {
+	arg := options.args[0]
+	options.args = options.args[1:]
+	return arg
+}
+
+func (options *longOptions) parseLong(arg string) error {
+	pair := strings.SplitN(arg, "=", 2)
+	name := strings.ToLower(pair[0])
+	if options.extra[name] {
+		if len(pair) == 1 && len(options.args) > 0 {
+			options.next()
+		}
+		return nil
+	}
+	field, ok := options.long[name]
+	if !ok {
+		return fmt.Errorf("unknown option --%s", pair[0])
+	}
+	if len(pair) == 2 {
+		return options.loader.setField(field, pair[1])
+	}
+	return options.setNext(field, "--"+pair[0])
+}
+
+func (options *longOptions) parseShort(arg string) error {
+	for i := 0; i < len(arg); i++ {
+		name := arg[i : i+1]
+		field, ok := options.short[name]
+		if !ok {
+			return fmt.Errorf("unknown option -%s", name)
+		}
+		if field.value.Kind() == reflect.Bool {
+			if err := options.loader.setField(field, "true"); err != nil {
+				return err
+			}
+			continue
+		}
+		if rest := arg[i+1:]; len(rest) > 0 {
+			return options.loader.setField(field, rest)
+		}
+		return options.setNext(field, "-"+name)
+	}
+	return nil
+}
+
+// setNext sets field from the next argument, or to true for bools.
+func (options *longOptions) setNext(field currentField, option string) error {
+	if field.value.Kind() == reflect.Bool {
+		return options.loader.setField(field, "true")
+	}
+	if len(options.args) == 0 {
+		return fmt.Errorf("option %s needs a value", option)
+	}
+	return options.loader.setField(field, options.next())
+}
@@ -1,14 +1,21 @@
 package configloader
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math/big"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"github.com/golang-collections/collections/queue"
 )
@@ -17,21 +24,68 @@ type currentField struct {
 	original reflect.StructField
 	value    reflect.Value
 	name     string
+	path     []string
+	prefixes []string
 	index    int
+	commit   func()
+	secret   bool
+	// setter is the Set method of unexported fields loaded with
+	// WithSetters.
+	setter reflect.Value
+	// origin is the path of the field in the struct, which stays
+	// the same when WithFieldMapping renames it.
+	origin []string
+}
+
+// id identifies the field in the target, whatever its mapped name.
+func (field currentField) id() string {
+	return strings.Join(field.origin, ".")
 }
 
 // Hook is something that loads data from a source
 // and stores it into your configuration struct
 // (here is an interface)
 type Hook interface {
-	run(interface{})
+	run(*ConfigLoader) error
+	source() string
 }
 
 // ConfigLoader loads data into a target (a config struct).
 // Data can come from different hooks.
 type ConfigLoader struct {
-	hooks  *queue.Queue
-	target interface{}
+	hooks        *queue.Queue
+	target       interface{}
+	lenient      bool
+	strictTarget bool
+	trimSpace    bool
+	warnings     []error
+	deferred     []deferredAction
+	source       string
+	callbacks    map[string][]FieldCallback
+	extraFlags   []string
+	skip         func(FieldInfo) bool
+	envPrefix    string
+	naming       NamingStrategy
+	panicOnError bool
+	failedField  string
+	emptyClears  bool
+	verbose      bool
+	emptyAsZero  bool
+	defaults     map[string]string
+	results      []HookResult
+	ran          []Hook
+	current      int
+	precedence   map[string]map[string]reflect.Value
+	timings      []HookCallback
+	decimalComma bool
+	noZeros      bool
+	written      map[string]bool
+	mapping      map[string][]string
+	secrets      map[string]bool
+	setters      bool
+	// warningsBefore is the number of warnings when the running
+	// hook started, to tell which ones it added.
+	warningsBefore int
 }
 
 // NewConfigLoaderFor creates a ConfigLoader for a target
@@ -39,9 +93,29 @@ type ConfigLoader struct {
 // a pointer to a empty struct instance.
 func NewConfigLoaderFor(target interface{}) *ConfigLoader {
 	return &ConfigLoader{
-		hooks:  queue.New(),
-		target: target,
+		hooks:   queue.New(),
+		target:  target,
+		current: -1,
+	}
+}
+
+// StandardPrecedence creates a ConfigLoader for target with the usual
+// chain of hooks: defaults, then the JSON file, then env vars, then
+// command line params, each one overriding the previous. The file hook
+// is skipped if file is empty. Env vars use the passed prefixes, or
+// CONFIG_ if there are none.
+func StandardPrecedence(target interface{}, file string, prefixes ...string) *ConfigLoader {
+	env := CreateEnvHook()
+	if len(prefixes) > 0 {
+		env = CreateEnvHookWithPrefixes(prefixes...)
+	}
+	loader := NewConfigLoaderFor(target).AddHook(CreateDefaultsHook())
+	if len(file) > 0 {
+		loader.AddHook(CreateFileHook(file))
 	}
+	return loader.
+		AddHook(env).
+		AddHook(CreateParamsHook())
 }
 
 // AddHook adds a new source to load data from.
@@ -50,18 +124,470 @@ func (loader *ConfigLoader) AddHook(hook Hook) *ConfigLoader {
 	return loader
 }
 
+// AddHooks adds many sources at once, in order.
+func (loader *ConfigLoader) AddHooks(hooks ...Hook) *ConfigLoader {
+	for _, hook := range hooks {
+		loader.AddHook(hook)
+	}
+	return loader
+}
+
+// WithLenientMode makes the loader skip values that cannot be
+// parsed instead of stopping. The field keeps the value it had
+// before, and the failure is stored as a warning that you can
+// read with Warnings after Retrieve.
+func (loader *ConfigLoader) WithLenientMode() *ConfigLoader {
+	loader.lenient = true
+	return loader
+}
+
+// WithTrimSpace makes the loader remove leading and trailing
+// whitespace from every value read by hooks, before parsing it.
+// JSON files decoded by CreateFileHook are not affected.
+func (loader *ConfigLoader) WithTrimSpace() *ConfigLoader {
+	loader.trimSpace = true
+	return loader
+}
+
+// WithStrictTarget makes loading fail with ErrNoSettableFields when
+// the target has no field the loader can set. Without it, that case
+// is only logged and stored as a warning.
+func (loader *ConfigLoader) WithStrictTarget() *ConfigLoader {
+	loader.strictTarget = true
+	return loader
+}
+
+// WithEnvPrefix changes the prefix used by env hooks created with
+// CreateEnvHook, which is CONFIG_ by default. Hooks created with
+// CreateEnvHookWithPrefixes keep their own prefixes. This lets you
+// namespace every var of a deployment, like TENANT1_:
+//
+//	loader.WithEnvPrefix("TENANT1_").AddHook(configloader.CreateEnvHook())
+func (loader *ConfigLoader) WithEnvPrefix(prefix string) *ConfigLoader {
+	loader.envPrefix = prefix
+	return loader
+}
+
+// WithNamingStrategy sets how hooks that look values up by key, like
+// the key value, registry and SQL hooks, map field names to keys. Each
+// of those hooks can still override it with its own strategy, so
+// sources with different conventions can feed the same struct.
+// Env vars and params keep their own naming.
+func (loader *ConfigLoader) WithNamingStrategy(naming NamingStrategy) *ConfigLoader {
+	loader.naming = naming
+	return loader
+}
+
+// namingFor returns the hook naming strategy if there is one, or
+// else the loader one. Without any, keys are the field names.
+func (loader *ConfigLoader) namingFor(naming NamingStrategy) NamingStrategy {
+	if naming != nil {
+		return naming
+	}
+	if loader.naming != nil {
+		return loader.naming
+	}
+	return func(name string) string { return name }
+}
+
+// WithPanicOnError makes Retrieve panic with a *ConfigError instead
+// of exiting, so programs can recover and show a friendly message.
+func (loader *ConfigLoader) WithPanicOnError() *ConfigLoader {
+	loader.panicOnError = true
+	return loader
+}
+
+// ConfigError is the value Retrieve panics with when the loader is
+// created WithPanicOnError.
+type ConfigError struct {
+	// Hook is the source of the failing hook, like "env". It is
+	// empty if loading failed validating the target.
+	Hook string
+	// Field is the name of the field that could not be set, if the
+	// error was about a single field.
+	Field string
+	Err   error
+}
+
+func (err *ConfigError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *ConfigError) Unwrap() error {
+	return err.Err
+}
+
+// WithVerbose makes the loader log each hook it runs, and each field
+// set with the source that set it and its new value. Values of secret
+// fields (see WithSecrets) are logged as [redacted].
+func (loader *ConfigLoader) WithVerbose() *ConfigLoader {
+	loader.verbose = true
+	return loader
+}
+
+// WithEmptyAsZero makes empty values for numeric and bool fields set
+// them to zero (or false) instead of failing to parse, for sources that
+// write empty strings to mean "use the default". Without it they are
+// an error, so mistakes are not hidden.
+func (loader *ConfigLoader) WithEmptyAsZero() *ConfigLoader {
+	loader.emptyAsZero = true
+	return loader
+}
+
+// ErrNoSettableFields means the target struct has no exported fields.
+var ErrNoSettableFields = errors.New("target has no settable fields, did you forget to export them?")
+
 // Retrieve loaded struct. It'll return a pointer to your struct.
-func (loaded ConfigLoader) Retrieve() interface{} {
-	for loaded.hooks.Len() > 0 {
-		hook := loaded.hooks.Dequeue().(Hook)
-		hook.run(loaded.target)
+// If any hook fails, the program exits logging the error.
+func (loader *ConfigLoader) Retrieve() interface{} {
+	if err := loader.load(); err != nil {
+		if loader.panicOnError {
+			panic(&ConfigError{Hook: loader.source, Field: loader.failedField, Err: err})
+		}
+		log.Fatalln(err)
+	}
+	return loader.target
+}
+
+// TryRetrieve works like Retrieve, but returns the error of
+// the first failing hook instead of exiting.
+func (loader *ConfigLoader) TryRetrieve() (interface{}, error) {
+	if err := loader.load(); err != nil {
+		return nil, err
+	}
+	return loader.target, nil
+}
+
+func (loader *ConfigLoader) load() error {
+	if err := loader.checkTarget(); err != nil {
+		return err
+	}
+	if err := loader.checkMapping(); err != nil {
+		return err
+	}
+	for loader.hooks.Len() > 0 {
+		hook := loader.hooks.Dequeue().(Hook)
+		loader.ran = append(loader.ran, hook)
+		loader.source = hook.source()
+		loader.failedField = ""
+		if loader.verbose {
+			log.Printf("Running %s hook", loader.source)
+		}
+		loader.startResult(hook)
+		err := loader.runTimed(hook)
+		loader.finishResult(err)
+		if err != nil {
+			return err
+		}
+	}
+	for _, action := range loader.deferred {
+		loader.source = action.source
+		loader.failedField = ""
+		loader.current = action.result
+		loader.warningsBefore = len(loader.warnings)
+		err := action.run()
+		loader.finishResult(err)
+		if err != nil {
+			return err
+		}
+	}
+	loader.deferred = nil
+	loader.resolvePrecedence()
+	loader.source = ""
+	if err := loader.checkImplicitZeros(); err != nil {
+		return err
+	}
+	if err := loader.validate(); err != nil {
+		return err
+	}
+	return afterLoad(loader.target)
+}
+
+func (loader *ConfigLoader) checkTarget() error {
+	settable := 0
+	count := func(field currentField) error {
+		settable++
+		return nil
+	}
+	if err := foreachField(loader.target, count); err != nil {
+		return err
+	}
+	if loader.setters {
+		foreachSetterField(loader.target, count)
+	}
+	if settable > 0 {
+		return nil
+	}
+	if loader.strictTarget {
+		return ErrNoSettableFields
+	}
+	log.Println("Warning:", ErrNoSettableFields)
+	loader.warnings = append(loader.warnings, ErrNoSettableFields)
+	return nil
+}
+
+// Warnings returns the problems found while loading that did not
+// stop it, like the values skipped in lenient mode.
+func (loader *ConfigLoader) Warnings() []error {
+	return loader.warnings
+}
+
+// WithSkipFields excludes from loading and validation every field for
+// which skip returns true. It is checked for each field every time a
+// hook walks the target (in foreachField), so skip can depend on
+// runtime state like feature flags or the current platform.
+func (loader *ConfigLoader) WithSkipFields(skip func(FieldInfo) bool) *ConfigLoader {
+	loader.skip = skip
+	return loader
+}
+
+func (loader *ConfigLoader) isSkipped(field currentField) bool {
+	return loader.skip != nil && loader.skip(describeField(field))
+}
+
+// prepareField applies the loader options which change how hooks see
+// a field: its name from WithFieldMapping and WithSecrets.
+func (loader *ConfigLoader) prepareField(field currentField) currentField {
+	field = loader.mapField(field)
+	field.secret = loader.isListedSecret(field)
+	return field
+}
+
+// foreachField walks the fields of the target the running hook
+// is allowed to set. A field with a configSource tag, like
+// configSource:"env,params", can only be set by those sources.
+// Fields excluded with WithSkipFields are never walked.
+// With WithSetters, unexported fields with a setter are walked after
+// the others.
+func (loader *ConfigLoader) foreachField(runAction func(currentField) error) error {
+	walk := func(field currentField) error {
+		field = loader.prepareField(field)
+		if !allowsSource(field, loader.source) || loader.isSkipped(field) {
+			return nil
+		}
+		return runAction(field)
+	}
+	if err := foreachField(loader.target, walk); err != nil {
+		return err
+	}
+	if loader.setters {
+		return foreachSetterField(loader.target, walk)
+	}
+	return nil
+}
+
+// protectFields hides the fields the running hook cannot set,
+// for hooks that decode the whole target at once. Call the returned
+// function when the hook is done to put them back.
+func (loader *ConfigLoader) protectFields() func() {
+	saved := make(map[int]reflect.Value)
+	position := 0
+	foreachField(loader.target, func(field currentField) error {
+		if !allowsSource(field, loader.source) || loader.isSkipped(field) {
+			value := reflect.New(field.value.Type()).Elem()
+			value.Set(field.value)
+			field.value.Set(reflect.Zero(field.value.Type()))
+			saved[position] = value
+		}
+		position++
+		return nil
+	})
+	return func() {
+		// Walk the target again: the hook may have allocated nested
+		// pointers, so protected fields can live somewhere new.
+		position := 0
+		foreachField(loader.target, func(field currentField) error {
+			if value, ok := saved[position]; ok {
+				field.value.Set(value)
+			}
+			position++
+			return nil
+		})
+	}
+}
+
+func allowsSource(field currentField, source string) bool {
+	sources, ok := field.original.Tag.Lookup("configSource")
+	if !ok {
+		return true
 	}
-	return loaded.target
+	for _, allowed := range strings.Split(sources, ",") {
+		if strings.TrimSpace(allowed) == source {
+			return true
+		}
+	}
+	return false
+}
+
+// setField sets a field from a hook. In lenient mode parse errors
+// are stored as warnings rather than returned.
+func (loader *ConfigLoader) setField(field currentField, rawValue string) error {
+	if loader.trimSpace {
+		rawValue = strings.TrimSpace(rawValue)
+	}
+	rawValue = loader.normalizeDecimal(field, rawValue)
+	if loader.emptyAsZero && len(strings.TrimSpace(rawValue)) == 0 && isNumericOrBool(field.value.Kind()) {
+		field.value.Set(reflect.Zero(field.value.Type()))
+		return loader.done(field, nil)
+	}
+	keep := loader.keepList(field)
+	err := setField(field, rawValue)
+	if err == nil && keep() {
+		return nil
+	}
+	return loader.done(field, err)
+}
+
+// setFieldItems sets a slice or array field from a hook, item by item.
+func (loader *ConfigLoader) setFieldItems(field currentField, items []string) error {
+	if loader.trimSpace {
+		for i, item := range items {
+			items[i] = strings.TrimSpace(item)
+		}
+	}
+	keep := loader.keepList(field)
+	err := setList(field.value, items, field.original.Tag)
+	if err != nil {
+		err = fieldError(field, err)
+	} else if keep() {
+		return nil
+	}
+	return loader.done(field, err)
+}
+
+// done finishes setting a field. On success it notifies the field
+// callbacks; in lenient mode errors become warnings.
+func (loader *ConfigLoader) done(field currentField, err error) error {
+	if err == nil {
+		if field.commit != nil {
+			field.commit()
+		}
+		loader.notify(field)
+		return nil
+	}
+	if loader.lenient {
+		loader.warnings = append(loader.warnings, err)
+		return nil
+	}
+	loader.failedField = field.name
+	return err
+}
+
+// FieldCallback is called after a field is set, with its name
+// and its new value.
+type FieldCallback func(name string, value interface{})
+
+// OnFieldSet registers a callback called each time a hook sets the
+// field with the given name (as used by params). Callbacks run while
+// hooks run, so before validation. Several callbacks for the same
+// field run in the order they were registered. Hooks decoding whole
+// JSON documents only call them for the fields whose value changed.
+func (loader *ConfigLoader) OnFieldSet(name string, callback FieldCallback) *ConfigLoader {
+	if loader.callbacks == nil {
+		loader.callbacks = make(map[string][]FieldCallback)
+	}
+	loader.callbacks[name] = append(loader.callbacks[name], callback)
+	return loader
+}
+
+func (loader *ConfigLoader) notify(field currentField) {
+	loader.recordField(field)
+	loader.recordPrecedence(field)
+	loader.recordWritten(field)
+	if loader.verbose {
+		value := formatValue(field.value)
+		if isSecret(field) {
+			value = "[redacted]"
+		}
+		log.Printf("Field %s set by %s: %s", field.name, loader.source, value)
+	}
+	for _, callback := range loader.callbacks[field.name] {
+		callback(field.name, field.value.Interface())
+	}
+}
+
+// notifyChanges is used by hooks that set the whole target at once.
+// It takes a copy of each field before the hook runs, and the returned
+// function notifies the fields that changed.
+func (loader *ConfigLoader) notifyChanges() func() {
+	if len(loader.callbacks) == 0 && !loader.verbose && !loader.noZeros && !hasPrecedence(loader.target) {
+		return func() {}
+	}
+	before := make([]reflect.Value, 0)
+	foreachField(loader.target, func(field currentField) error {
+		before = append(before, deepCopy(field.value))
+		return nil
+	})
+	return func() {
+		position := 0
+		foreachField(loader.target, func(field currentField) error {
+			if !reflect.DeepEqual(before[position].Interface(), field.value.Interface()) {
+				loader.notify(loader.prepareField(field))
+			}
+			position++
+			return nil
+		})
+	}
+}
+
+// OptionalHook wraps a hook whose failure should not stop loading.
+type OptionalHook struct {
+	hook Hook
+}
+
+// Optional marks a hook as best-effort: if it fails, its error is
+// stored as a warning and the next hooks run anyway. Values the
+// hook set before failing are kept.
+func Optional(hook Hook) OptionalHook {
+	return OptionalHook{hook: hook}
+}
+
+func (optional OptionalHook) source() string {
+	return optional.hook.source()
+}
+
+func (optional OptionalHook) run(loader *ConfigLoader) error {
+	if err := optional.hook.run(loader); err != nil {
+		loader.warnings = append(loader.warnings, err)
+	}
+	return nil
+}
+
+// RequiredHook wraps a hook which must load some value.
+type RequiredHook struct {
+	hook Hook
+}
+
+// Required marks a hook whose source must be there. Like every hook,
+// its failure stops loading, but so does loading nothing at all, as
+// when none of its env vars is set or CreateXDGFileHook finds no file.
+func Required(hook Hook) RequiredHook {
+	return RequiredHook{hook: hook}
+}
+
+func (required RequiredHook) source() string {
+	return required.hook.source()
+}
+
+func (required RequiredHook) run(loader *ConfigLoader) error {
+	target := reflect.ValueOf(loader.target).Elem()
+	before := deepCopy(target)
+	fields := loader.resultFields()
+	if err := required.hook.run(loader); err != nil {
+		return err
+	}
+	if loader.resultFields() > fields || !reflect.DeepEqual(before.Interface(), target.Interface()) {
+		return nil
+	}
+	return fmt.Errorf("required %s loaded no values", describeHook(required.hook))
 }
 
 // ConfigFileHook will load data from a JSON file.
 type ConfigFileHook struct {
-	file string
+	file         string
+	name         string
+	quotedValues bool
+	strictTypes  bool
 }
 
 // CreateFileHook passing JSON file.
@@ -69,142 +595,928 @@ func CreateFileHook(file string) ConfigFileHook {
 	return ConfigFileHook{file: file}
 }
 
-func (hook ConfigFileHook) run(target interface{}) {
+// WithQuotedValues makes the hook accept numbers and bools written
+// as JSON strings, like {"Port": "8080"}, for numeric and bool fields.
+// It is off by default because it can hide real type mistakes.
+func (hook ConfigFileHook) WithQuotedValues() ConfigFileHook {
+	hook.quotedValues = true
+	return hook
+}
+
+func (hook ConfigFileHook) source() string {
+	return "file"
+}
+
+func (hook ConfigFileHook) run(loader *ConfigLoader) error {
+	return hook.layerError(hook.load(loader))
+}
+
+func (hook ConfigFileHook) load(loader *ConfigLoader) error {
 	file, err := os.OpenFile(hook.file, os.O_RDONLY, os.ModePerm)
 	if err != nil {
-		log.Fatalln("Error while reading config file: ", err)
+		return fmt.Errorf("error while reading config file: %w", err)
 	}
 	defer file.Close()
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(target)
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("error while reading config file: %w", err)
+	}
+	if hook.strictTypes {
+		if hook.quotedValues {
+			return errStrictQuoted
+		}
+		if err := checkJSONDocument(loader.target, data); err != nil {
+			return fmt.Errorf("error while decoding config file: %w", err)
+		}
+	}
+	if err := loader.decodeJSON(data, hook.quotedValues); err != nil {
+		return fmt.Errorf("error while decoding config file: %w", err)
+	}
+	return nil
+}
+
+// decodeJSON decodes a JSON document into the target. Decoding
+// only overwrites the keys present in data, so decoding several
+// documents one after another merges them, the last one winning.
+func (loader *ConfigLoader) decodeJSON(data []byte, quotedValues bool) error {
+	var err error
+	if hasGroups(reflect.TypeOf(loader.target)) {
+		data, err = regroupJSON(loader.target, data)
+		if err != nil {
+			return err
+		}
+	}
+	if quotedValues {
+		data, err = unquoteJSONValues(loader.target, data)
+		if err != nil {
+			return err
+		}
+	}
+	if hasDurations(reflect.TypeOf(loader.target)) {
+		data, err = parseJSONDurations(loader.target, data)
+		if err != nil {
+			return err
+		}
+	}
+	err = loader.decodeTarget(func(target interface{}) error {
+		return decodeJSONTarget(target, data)
+	})
+	if err != nil {
+		return err
+	}
+	loader.recordJSONKeys(data)
+	return nil
+}
+
+// decodeTarget runs decode, which fills the whole target at once,
+// keeping the fields the running hook is not allowed to set, and
+// notifying the ones it changed.
+func (loader *ConfigLoader) decodeTarget(decode func(target interface{}) error) error {
+	notify := loader.notifyChanges()
+	keep := loader.keepLists()
+	restore := loader.protectFields()
+	err := decode(loader.target)
+	restore()
+	keep()
 	if err != nil {
-		log.Fatalln("Error while decoding config file", err)
+		return err
 	}
+	notify()
+	return nil
 }
 
-// ParamsHook will load data from command line params.
+func decodeJSONTarget(target interface{}, data []byte) error {
+	if hasRegisteredTypes() {
+		if err := prepareInterfaces(target, data); err != nil {
+			return err
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	return decoder.Decode(target)
+}
+
+// ParamsHook will load data from command line params. Slice, array
+// and map fields can be passed several times, like -tag a -tag b,
+// and every value is kept.
 type ParamsHook struct {
-	flags []*string
+	flags   []flag.Value
+	flagSet *flag.FlagSet
 }
 
 // CreateParamsHook creates a hook which loads
-// command line params.
+// command line params. Flags are registered in the
+// default flag.CommandLine, so invalid params exit the program.
 func CreateParamsHook() ParamsHook {
 	return ParamsHook{
-		flags: make([]*string, 0),
+		flags:   make([]flag.Value, 0),
+		flagSet: flag.CommandLine,
 	}
 }
 
-func (hook ParamsHook) run(target interface{}) {
-	hook.readFlagsFromStructMetadata(target)
-	flag.Parse()
+// CreateParamsHookWithErrorHandling creates a hook which loads
+// command line params using its own flag.FlagSet with the passed
+// error handling. With flag.ContinueOnError, invalid params are
+// returned as errors by TryRetrieve.
+func CreateParamsHookWithErrorHandling(handling flag.ErrorHandling) ParamsHook {
+	return ParamsHook{
+		flags:   make([]flag.Value, 0),
+		flagSet: flag.NewFlagSet(os.Args[0], handling),
+	}
+}
+
+func (hook ParamsHook) source() string {
+	return "params"
+}
+
+func (hook ParamsHook) run(loader *ConfigLoader) error {
+	hook.readFlagsFromStructMetadata(loader)
+	if err := hook.flagSet.Parse(os.Args[1:]); err != nil {
+		return fmt.Errorf("error while parsing params: %w", err)
+	}
 	i := 0
-	foreachField(target, func(field currentField) {
-		if i < len(hook.flags) && len(*hook.flags[i]) > 0 {
-			setField(field.value, *hook.flags[i])
+	return loader.foreachField(func(field currentField) error {
+		if i >= len(hook.flags) {
+			return nil
 		}
+		value := hook.flags[i]
 		i++
+		if repeated, ok := value.(*repeatedFlag); ok {
+			if len(repeated.values) == 0 {
+				return nil
+			}
+			return loader.setField(field, repeated.join(getTagOr(field.original.Tag, "configSeparator", defaultSeparator)))
+		}
+		if raw := value.String(); len(raw) > 0 {
+			return loader.setField(field, raw)
+		}
+		return nil
 	})
 }
 
-func (hook *ParamsHook) readFlagsFromStructMetadata(target interface{}) {
-	foreachField(target, func(field currentField) {
-		hook.flags = append(hook.flags, flag.String(field.name, "", field.name))
+// readFlagsFromStructMetadata registers a flag for each field. Flags
+// registered by an earlier run, before a Reset, are cleared and reused,
+// since a flag.FlagSet can't define the same flag twice.
+func (hook *ParamsHook) readFlagsFromStructMetadata(loader *ConfigLoader) {
+	loader.foreachField(func(field currentField) error {
+		if existing := hook.flagSet.Lookup(field.name); existing != nil {
+			if repeated, ok := existing.Value.(*repeatedFlag); ok {
+				repeated.values = nil
+			} else {
+				existing.Value.Set("")
+			}
+			hook.flags = append(hook.flags, existing.Value)
+			return nil
+		}
+		switch field.value.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			value := new(repeatedFlag)
+			hook.flagSet.Var(value, field.name, field.name)
+			hook.flags = append(hook.flags, value)
+		default:
+			hook.flagSet.String(field.name, "", field.name)
+			hook.flags = append(hook.flags, hook.flagSet.Lookup(field.name).Value)
+		}
+		return nil
 	})
+	for _, name := range loader.extraFlags {
+		if hook.flagSet.Lookup(name) == nil {
+			hook.flagSet.String(name, "", "config file")
+		}
+	}
 }
 
 // EnvHook loads data from env vars
-type EnvHook struct{}
+type EnvHook struct {
+	prefixes    []string
+	fileSecrets bool
+	bare        bool
+	unused      unusedCheck
+	delimiter   string
+	composed    bool
+	defaulted   bool
+	naming      NamingStrategy
+	presence    bool
+	strict      bool
+	allowed     map[string]bool
+}
 
 // CreateEnvHook creates a hook which loads data from
-// env vars.
+// env vars starting with CONFIG_, or the prefix set with
+// WithEnvPrefix.
 func CreateEnvHook() EnvHook {
-	return EnvHook{}
+	hook := CreateEnvHookWithPrefixes(defaultEnvPrefix)
+	hook.defaulted = true
+	return hook
 }
 
-func (hook EnvHook) run(target interface{}) {
-	foreachField(target, func(field currentField) {
-		env := os.Getenv(hook.formatEnvVar(field.name))
-		if len(env) > 0 {
-			setField(field.value, env)
+const defaultEnvPrefix = "CONFIG_"
+
+// CreateEnvHookWithPrefixes creates a hook which loads data from
+// env vars starting with any of the passed prefixes. Prefixes are
+// tried in order for each field, and the first one with a non empty
+// variable wins. For example, with prefixes SVC_A_ and SVC_B_,
+// SVC_A_URL takes precedence over SVC_B_URL.
+func CreateEnvHookWithPrefixes(prefixes ...string) EnvHook {
+	return EnvHook{prefixes: prefixes}
+}
+
+// WithFileSecrets makes the hook read a value from a file when its
+// env var is not set, but the same var ending in _FILE is. For example,
+// if CONFIG_PASSWORD is empty and CONFIG_PASSWORD_FILE=/run/secrets/db,
+// the trimmed contents of /run/secrets/db are used. This is how
+// Docker secrets are usually passed.
+func (hook EnvHook) WithFileSecrets() EnvHook {
+	hook.fileSecrets = true
+	return hook
+}
+
+func (hook EnvHook) source() string {
+	return "env"
+}
+
+// WithoutPrefix makes the hook match env vars named just like the
+// field, in uppercase, ignoring both the hook prefixes and the struct
+// configPrefix. For example, the Port field of a struct with
+// configPrefix "db" is read from PORT instead of CONFIG_DBPORT.
+func (hook EnvHook) WithoutPrefix() EnvHook {
+	hook.bare = true
+	return hook
+}
+
+// WithDelimiter makes the hook compose env var names from the hook
+// prefix, the configPrefix of every enclosing struct and the field
+// name, joining the last ones with delimiter. For example, with "_"
+// the Host field of a struct with configPrefix "primary", inside one
+// with configPrefix "db", is read from CONFIG_DB_PRIMARY_HOST instead
+// of CONFIG_PRIMARYHOST. Structs without configPrefix add nothing.
+func (hook EnvHook) WithDelimiter(delimiter string) EnvHook {
+	hook.delimiter = delimiter
+	hook.composed = true
+	return hook
+}
+
+// WithNaming makes the hook name env vars with the hook prefix
+// followed by naming applied to the field name, instead of the field
+// name in uppercase. The loader WithNamingStrategy does not change
+// env vars, so this is how to use another convention for them.
+func (hook EnvHook) WithNaming(naming NamingStrategy) EnvHook {
+	hook.naming = naming
+	return hook
+}
+
+// WithPresenceBools makes bool fields true when their env var is set,
+// even if it is empty, so CONFIG_DEBUG= turns Debug on. Without it,
+// empty vars are ignored as if they were not set. Non empty values
+// are still parsed, so CONFIG_DEBUG=false keeps working.
+func (hook EnvHook) WithPresenceBools() EnvHook {
+	hook.presence = true
+	return hook
+}
+
+func (hook EnvHook) run(loader *ConfigLoader) error {
+	if hook.defaulted && len(loader.envPrefix) > 0 {
+		hook.prefixes = []string{loader.envPrefix}
+	}
+	if hook.strict && len(hook.requiredPrefixes()) == 0 {
+		return errEnvPrefixRequired
+	}
+	err := loader.foreachField(func(field currentField) error {
+		for _, name := range hook.envVarNames(field) {
+			env, err := hook.lookup(name)
+			if err != nil {
+				return err
+			}
+			if len(env) > 0 {
+				return loader.setField(field, env)
+			}
+			if _, ok := hook.lookupEnv(name); ok && hook.presence && field.value.Kind() == reflect.Bool {
+				return loader.setField(field, "true")
+			}
+			if kind := field.value.Kind(); kind == reflect.Slice || kind == reflect.Array {
+				if items := hook.lookupIndexed(name); len(items) > 0 {
+					return loader.setFieldItems(field, items)
+				}
+			}
 		}
+		if pattern, ok := field.original.Tag.Lookup("configCapture"); ok && field.value.Kind() == reflect.Map {
+			return hook.capture(loader, field, pattern)
+		}
+		if isStructMap(field.value.Type()) {
+			return hook.loadStructMap(loader, field)
+		}
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+	return hook.checkUnused(loader)
+}
+
+// envVarNames returns the env vars that can set field, in order.
+// A configEnv tag replaces them all with the exact var it names.
+// With WithRequiredPrefix, names without a prefix are left out.
+func (hook EnvHook) envVarNames(field currentField) []string {
+	names := hook.fieldEnvVarNames(field)
+	if hook.strict {
+		return hook.withPrefix(names)
+	}
+	return names
+}
+
+func (hook EnvHook) fieldEnvVarNames(field currentField) []string {
+	if name := field.original.Tag.Get("configEnv"); len(name) > 0 {
+		return []string{name}
+	}
+	if hook.bare {
+		return []string{strings.ToUpper(field.path[len(field.path)-1])}
+	}
+	names := make([]string, 0, len(hook.prefixes))
+	for _, prefix := range hook.prefixes {
+		names = append(names, hook.formatEnvVar(prefix, field))
+	}
+	return names
 }
 
-func (hook *EnvHook) formatEnvVar(name string) string {
+// lookupIndexed reads slices written as one var per item: NAME_0,
+// NAME_1 and so on. It stops at the first missing index, so with
+// NAME_0 and NAME_2 only the first item is read.
+func (hook EnvHook) lookupIndexed(name string) []string {
+	items := make([]string, 0)
+	for i := 0; ; i++ {
+		item, ok := hook.lookupEnv(fmt.Sprintf("%s_%d", name, i))
+		if !ok {
+			return items
+		}
+		items = append(items, item)
+	}
+}
+
+func (hook EnvHook) lookup(name string) (string, error) {
+	env, _ := hook.lookupEnv(name)
+	if len(env) > 0 || !hook.fileSecrets {
+		return env, nil
+	}
+	path, _ := hook.lookupEnv(name + "_FILE")
+	if len(path) == 0 {
+		return "", nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error while reading %s_FILE: %w", name, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func (hook *EnvHook) formatEnvVar(prefix string, field currentField) string {
+	name := field.name
+	if hook.composed {
+		parts := append(append([]string{}, field.prefixes...), field.path[len(field.path)-1])
+		name = strings.Join(parts, hook.delimiter)
+	}
+	if hook.naming != nil {
+		return fmt.Sprintf("%s%s", prefix, hook.naming(name))
+	}
 	upperName := strings.ToUpper(name)
-	return fmt.Sprintf("CONFIG_%s", upperName)
+	return fmt.Sprintf("%s%s", prefix, upperName)
+}
+
+const (
+	defaultSeparator         = ","
+	defaultKeyValueSeparator = "="
+)
+
+// setField parses rawValue and stores it into field. Slices are
+// read as a list separated by the configSeparator tag (a comma by
+// default), and maps as a list of key=value pairs, using the
+// configKeyValueSeparator tag to split keys from values.
+func setField(field currentField, rawValue string) error {
+	rawValue, err := applyCase(field, rawValue)
+	if err != nil {
+		return fmt.Errorf("field '%s': %w", field.name, err)
+	}
+	if field.setter.IsValid() {
+		return callSetter(field, rawValue)
+	}
+	if err := setValue(field.value, rawValue, field.original.Tag); err != nil {
+		return fieldError(field, err)
+	}
+	return nil
+}
+
+func isNumericOrBool(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// fieldError adds the field name to a conversion error. Secret
+// fields never show their value: the error is replaced by a
+// generic one saying the value is [redacted].
+func fieldError(field currentField, err error) error {
+	if isSecret(field) {
+		return fmt.Errorf("field '%s': value [redacted] is not a valid %s", field.name, field.value.Type())
+	}
+	return fmt.Errorf("field '%s': %w", field.name, err)
+}
+
+func isSecret(field currentField) bool {
+	return field.secret || isTagEnabled(field.original.Tag, "configSecret")
+}
+
+func setValue(value reflect.Value, rawValue string, tag reflect.StructTag) error {
+	if converter, ok := lookupConverter(value.Type()); ok {
+		return setConverted(value, rawValue, converter)
+	}
+	if value.Type() == timeType {
+		return setTime(value, rawValue, tag)
+	}
+	if ok, err := setBig(value, rawValue); ok {
+		return err
+	}
+	if ok, err := setRegexp(value, rawValue); ok {
+		return err
+	}
+	if value.Type() == durationType {
+		return setDuration(value, rawValue, tag)
+	}
+	if ok, err := setText(value, rawValue); ok {
+		return err
+	}
+	if value.Kind() == reflect.Ptr {
+		pointer := reflect.New(value.Type().Elem())
+		if err := setValue(pointer.Elem(), rawValue, tag); err != nil {
+			return err
+		}
+		value.Set(pointer)
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		return setList(value, splitList(rawValue, getTagOr(tag, "configSeparator", defaultSeparator)), tag)
+	case reflect.Map:
+		items := splitList(rawValue, getTagOr(tag, "configSeparator", defaultSeparator))
+		kvSeparator := getTagOr(tag, "configKeyValueSeparator", defaultKeyValueSeparator)
+		result := reflect.MakeMapWithSize(value.Type(), len(items))
+		for _, item := range items {
+			pair := strings.SplitN(item, kvSeparator, 2)
+			if len(pair) != 2 {
+				return fmt.Errorf("invalid map entry '%s': expected key%svalue", item, kvSeparator)
+			}
+			key := reflect.New(value.Type().Key()).Elem()
+			if err := setElem(key, pair[0], tag); err != nil {
+				return err
+			}
+			elem := reflect.New(value.Type().Elem()).Elem()
+			if err := setElem(elem, pair[1], tag); err != nil {
+				return err
+			}
+			result.SetMapIndex(key, elem)
+		}
+		value.Set(result)
+	default:
+		return setScalar(value, rawValue)
+	}
+	return nil
+}
+
+// setList sets a slice or an array from its items. Arrays need
+// exactly as many items as their length.
+func setList(value reflect.Value, items []string, tag reflect.StructTag) error {
+	if value.Kind() == reflect.Array {
+		return setArray(value, items, tag)
+	}
+	slice := reflect.MakeSlice(value.Type(), 0, len(items))
+	for i, item := range items {
+		elem := reflect.New(value.Type().Elem()).Elem()
+		if err := setElem(elem, item, tag); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	value.Set(slice)
+	return nil
 }
 
-func setField(field reflect.Value, rawValue string) {
-	const (
-		bitSize int = 64
-		base    int = 10
-	)
-	switch field.Type().Name() {
+func setArray(value reflect.Value, items []string, tag reflect.StructTag) error {
+	if len(items) != value.Len() {
+		return fmt.Errorf("expected %d items, got %d", value.Len(), len(items))
+	}
+	array := reflect.New(value.Type()).Elem()
+	for i, item := range items {
+		if err := setElem(array.Index(i), item, tag); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	value.Set(array)
+	return nil
+}
+
+// setElem sets an element of a slice or map. Elements are
+// always scalars: there is no way to tell apart the separators
+// of nested lists.
+func setElem(value reflect.Value, rawValue string, tag reflect.StructTag) error {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if isTextUnmarshaler(value.Type()) {
+			break
+		}
+		return fmt.Errorf("unsupported element type %s: nested lists are not supported", value.Type())
+	}
+	return setValue(value, strings.TrimSpace(rawValue), tag)
+}
+
+func setScalar(field reflect.Value, rawValue string) error {
+	const base int = 10
+	switch field.Kind() {
 	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	case reflect.String:
 		field.SetString(rawValue)
-	case "int", "int16", "int32", "int64":
-		i, err := strconv.Atoi(rawValue)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(rawValue, base, field.Type().Bits())
+		if errors.Is(err, strconv.ErrRange) {
+			return fmt.Errorf("value '%s' overflows %s", rawValue, field.Type())
+		}
 		if err != nil {
-			log.Fatalln(err)
+			return fmt.Errorf("value '%s' is not a valid integer", rawValue)
+		}
+		field.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		// Parse with the width of the field, so values like 1e39 are
+		// an error for float32 instead of becoming +Inf.
+		f, err := strconv.ParseFloat(rawValue, field.Type().Bits())
+		if errors.Is(err, strconv.ErrRange) || (err == nil && field.OverflowFloat(f)) {
+			return fmt.Errorf("value '%s' overflows %s", rawValue, field.Type())
 		}
-		field.SetInt(int64(i))
-	case "float", "float64":
-		i, err := strconv.ParseFloat(rawValue, bitSize)
 		if err != nil {
-			log.Fatalln(err)
+			return fmt.Errorf("value '%s' is not a valid number", rawValue)
 		}
-		field.SetFloat(i)
-	case "bool":
+		field.SetFloat(f)
+	case reflect.Bool:
 		i, err := strconv.ParseBool(rawValue)
 		if err != nil {
-			log.Fatalln(err)
+			return err
 		}
 		field.SetBool(i)
-	case "uint", "uint16", "uint32", "uint64":
-		i, err := strconv.ParseUint(rawValue, base, bitSize)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(rawValue, base, field.Type().Bits())
+		if errors.Is(err, strconv.ErrRange) {
+			return fmt.Errorf("value '%s' overflows %s", rawValue, field.Type())
+		}
 		if err != nil {
-			log.Fatalln(err)
+			return fmt.Errorf("value '%s' is not a valid unsigned integer", rawValue)
 		}
 		field.SetUint(i)
 	}
+	return nil
+}
+
+// applyCase converts rawValue as the configCase tag says:
+// "upper", "lower" or "title". It only applies to string fields.
+func applyCase(field currentField, rawValue string) (string, error) {
+	mode, ok := field.original.Tag.Lookup("configCase")
+	if !ok {
+		return rawValue, nil
+	}
+	if field.value.Kind() != reflect.String {
+		return "", fmt.Errorf("configCase can only be used with string fields, not %s", field.value.Type())
+	}
+	switch mode {
+	case "upper":
+		return strings.ToUpper(rawValue), nil
+	case "lower":
+		return strings.ToLower(rawValue), nil
+	case "title":
+		return toTitle(rawValue), nil
+	default:
+		return "", fmt.Errorf("unknown configCase '%s': expected upper, lower or title", mode)
+	}
+}
+
+// toTitle uppercases the first letter of each word and
+// lowercases the rest.
+func toTitle(value string) string {
+	words := strings.Split(strings.ToLower(value), " ")
+	for i, word := range words {
+		runes := []rune(word)
+		if len(runes) > 0 {
+			runes[0] = unicode.ToUpper(runes[0])
+			words[i] = string(runes)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// setTime parses a time using the configTimeFormat tag (RFC 3339
+// by default). Times written without an offset are read in the
+// location named by the configTimeZone tag, or UTC if it is missing.
+func setTime(value reflect.Value, rawValue string, tag reflect.StructTag) error {
+	format := getTagOr(tag, "configTimeFormat", time.RFC3339)
+	location := time.UTC
+	if zone := tag.Get("configTimeZone"); len(zone) > 0 {
+		var err error
+		location, err = time.LoadLocation(zone)
+		if err != nil {
+			return fmt.Errorf("invalid time zone '%s': %w", zone, err)
+		}
+	}
+	t, err := time.ParseInLocation(format, rawValue, location)
+	if err != nil {
+		return err
+	}
+	value.Set(reflect.ValueOf(t))
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var durationUnits = map[string]time.Duration{
+	"nanoseconds":  time.Nanosecond,
+	"microseconds": time.Microsecond,
+	"milliseconds": time.Millisecond,
+	"seconds":      time.Second,
+	"minutes":      time.Minute,
+	"hours":        time.Hour,
+}
+
+// setDuration parses a duration like "1m30s". Plain numbers are read
+// in the unit named by the configDurationUnit tag, like "seconds", or
+// as nanoseconds if it is missing, as they were before durations were
+// recognized. Values with their own unit always win over the tag.
+// Fractions, like 1.5 seconds, must give whole nanoseconds.
+func setDuration(value reflect.Value, rawValue string, tag reflect.StructTag) error {
+	unit, err := durationUnit(tag)
+	if err != nil {
+		return err
+	}
+	duration, err := parseDuration(rawValue, unit)
+	if err != nil {
+		return err
+	}
+	value.SetInt(int64(duration))
+	return nil
+}
+
+// durationUnit returns the unit named by the configDurationUnit tag,
+// or nanoseconds if it is missing.
+func durationUnit(tag reflect.StructTag) (time.Duration, error) {
+	name := getTagOr(tag, "configDurationUnit", "nanoseconds")
+	unit, ok := durationUnits[name]
+	if !ok {
+		return 0, fmt.Errorf("invalid configDurationUnit '%s'", name)
+	}
+	return unit, nil
+}
+
+// parseDuration reads rawValue with time.ParseDuration first, and if
+// that fails, as a plain number of unit. Only "0" is valid for both,
+// and it means zero either way.
+func parseDuration(rawValue string, unit time.Duration) (time.Duration, error) {
+	if duration, err := time.ParseDuration(rawValue); err == nil {
+		return duration, nil
+	}
+	duration, ok, err := parseDurationAmount(rawValue, unit)
+	if !ok {
+		return 0, fmt.Errorf("value '%s' is not a valid duration", rawValue)
+	}
+	return duration, err
+}
+
+// parseDurationAmount reads rawValue as a plain number of unit. It
+// reports false if rawValue is not a number, and an error if the
+// number does not give whole nanoseconds or overflows a duration.
+func parseDurationAmount(rawValue string, unit time.Duration) (time.Duration, bool, error) {
+	if amount, err := strconv.ParseInt(rawValue, 10, 64); err == nil {
+		duration := time.Duration(amount) * unit
+		if amount != 0 && duration/unit != time.Duration(amount) {
+			return 0, true, fmt.Errorf("value '%s' overflows a duration", rawValue)
+		}
+		return duration, true, nil
+	}
+	float, err := strconv.ParseFloat(rawValue, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if float == 0 {
+			return 0, true, fmt.Errorf("value '%s' is not a whole number of nanoseconds", rawValue)
+		}
+		return 0, true, fmt.Errorf("value '%s' overflows a duration", rawValue)
+	}
+	if err != nil {
+		return 0, false, nil
+	}
+	amount, ok := new(big.Rat).SetString(rawValue)
+	if !ok {
+		return 0, false, nil
+	}
+	amount.Mul(amount, new(big.Rat).SetInt64(int64(unit)))
+	if !amount.IsInt() {
+		return 0, true, fmt.Errorf("value '%s' is not a whole number of nanoseconds", rawValue)
+	}
+	if !amount.Num().IsInt64() {
+		return 0, true, fmt.Errorf("value '%s' overflows a duration", rawValue)
+	}
+	return time.Duration(amount.Num().Int64()), true, nil
+}
+
+func splitList(rawValue, separator string) []string {
+	if len(strings.TrimSpace(rawValue)) == 0 {
+		return []string{}
+	}
+	return strings.Split(rawValue, separator)
+}
+
+func getTagOr(tag reflect.StructTag, key, fallback string) string {
+	if value, ok := tag.Lookup(key); ok && len(value) > 0 {
+		return value
+	}
+	return fallback
 }
 
 type target_t struct {
-	value  reflect.Value
-	typ    reflect.Type
-	prefix string
-}
-
-func foreachField(target interface{}, runAction func(currentField)) {
-	foreachFieldValue(target_t{
-		value:  reflect.ValueOf(target).Elem(),
-		typ:    reflect.TypeOf(target).Elem(),
-		prefix: "",
-	}, runAction)
-}
-
-func foreachFieldValue(target target_t, runAction func(currentField)) {
-	for i := 0; i < target.value.NumField(); i++ {
-		currentValue := target.value.Field(i)
-		currentType := target.typ.Field(i)
-		if currentType.Type.Kind() == reflect.Struct {
-			foreachFieldValue(target_t{
-				value:  currentValue,
-				typ:    currentType.Type,
-				prefix: currentType.Tag.Get("configPrefix"),
-			}, runAction)
-		} else if currentValue.IsValid() && currentValue.CanAddr() && currentValue.CanSet() {
+	typ      reflect.Type
+	prefix   string
+	path     []string
+	prefixes []string
+	index    []int
+	parents  []reflect.Type
+	// fields are the Go names of the structs and fields walked to
+	// get here, starting with the target type, for errors.
+	fields []string
+}
+
+// fieldMeta is the part of a currentField that only depends on the
+// struct type, so it can be computed once and reused.
+type fieldMeta struct {
+	original reflect.StructField
+	name     string
+	path     []string
+	prefixes []string
+	index    []int
+	tagErr   error
+	// cycleErr is set for pointers back to a struct containing them.
+	cycleErr error
+}
+
+// fieldsCache maps each struct type to its []fieldMeta. All hooks
+// walk the same target, so caching avoids repeating the reflection
+// work on every run.
+var fieldsCache sync.Map
+
+func foreachField(target interface{}, runAction func(currentField) error) error {
+	value := reflect.ValueOf(target).Elem()
+	for _, meta := range getTypeFields(value.Type()) {
+		currentValue, commit := fieldByIndex(value, meta.index)
+		if !currentValue.IsValid() || !currentValue.CanAddr() || !currentValue.CanSet() {
+			continue
+		}
+		if meta.cycleErr != nil {
+			return meta.cycleErr
+		}
+		if meta.tagErr != nil {
+			return fmt.Errorf("field '%s': %w", meta.name, meta.tagErr)
+		}
+		err := runAction(currentField{
+			original: meta.original,
+			value:    currentValue,
+			name:     meta.name,
+			path:     meta.path,
+			prefixes: meta.prefixes,
+			index:    meta.index[len(meta.index)-1],
+			commit:   commit,
+			origin:   meta.path,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByIndex works like reflect.Value.FieldByIndex, but it does not
+// panic on nil pointers to nested structs. When it finds one, the
+// field is resolved inside a new struct which is not in the target
+// yet, and the returned commit function stores it there. This way
+// hooks allocate a pointer chain only when they set a value inside
+// it. commit is nil when every pointer on the way was set.
+func fieldByIndex(value reflect.Value, index []int) (reflect.Value, func()) {
+	var commit func()
+	for i, position := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				allocated := reflect.New(value.Type().Elem())
+				if commit == nil {
+					pointer := value
+					commit = func() { pointer.Set(allocated) }
+				} else {
+					value.Set(allocated)
+				}
+				value = allocated
+			}
+			value = value.Elem()
+		}
+		value = value.Field(position)
+	}
+	return value, commit
+}
+
+func getTypeFields(typ reflect.Type) []fieldMeta {
+	if cached, ok := fieldsCache.Load(typ); ok {
+		return cached.([]fieldMeta)
+	}
+	fields := foreachFieldType(target_t{
+		typ:      typ,
+		prefix:   "",
+		path:     []string{},
+		prefixes: []string{},
+		index:    []int{},
+		parents:  []reflect.Type{typ},
+		fields:   []string{typ.Name()},
+	}, make([]fieldMeta, 0))
+	fieldsCache.Store(typ, fields)
+	return fields
+}
+
+func foreachFieldType(target target_t, fields []fieldMeta) []fieldMeta {
+	for i := 0; i < target.typ.NumField(); i++ {
+		currentType, tagErr := expandConfigTag(target.typ.Field(i))
+		index := appendIndex(target.index, i)
+		if nested, ok := nestedStruct(currentType.Type, target.parents); ok && tagErr == nil {
+			prefix := currentType.Tag.Get("configPrefix")
+			prefixes := target.prefixes
+			if len(prefix) > 0 {
+				prefixes = appendPath(prefixes, prefix)
+			}
+			fields = foreachFieldType(target_t{
+				typ:      nested,
+				prefix:   prefix,
+				path:     appendPath(target.path, getGroupName(currentType, prefix)),
+				prefixes: prefixes,
+				index:    index,
+				parents:  append(append([]reflect.Type{}, target.parents...), nested),
+				fields:   appendPath(target.fields, currentType.Name),
+			}, fields)
+		} else {
 			currentName := getFieldName(currentType)
-			runAction(currentField{
+			meta := fieldMeta{
 				original: currentType,
-				value:    currentValue,
 				name:     fmt.Sprintf("%s%s", target.prefix, currentName),
-				index:    i,
-			})
+				path:     appendPath(target.path, currentName),
+				prefixes: target.prefixes,
+				index:    index,
+				tagErr:   tagErr,
+			}
+			if isRecursive(currentType.Type, target.parents) {
+				meta.cycleErr = fmt.Errorf("field '%s': recursive type %s",
+					strings.Join(appendPath(target.fields, currentType.Name), "."), currentType.Type)
+			}
+			fields = append(fields, meta)
+		}
+	}
+	return fields
+}
+
+// nestedStruct returns the struct walked for a field of type typ:
+// typ itself for structs, or the pointed struct for pointers to
+// structs. It reports false for every other field, and for pointers
+// back to a struct which is already being walked, since walking them
+// would never end (see isRecursive).
+func nestedStruct(typ reflect.Type, parents []reflect.Type) (reflect.Type, bool) {
+	if isRecursive(typ, parents) {
+		return nil, false
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || isValueStruct(typ) {
+		return nil, false
+	}
+	return typ, true
+}
+
+// isRecursive reports whether typ is a pointer back to one of the
+// structs containing it, like Next *Node inside Node. Loading such a
+// field is an error, since the walk would never end.
+func isRecursive(typ reflect.Type, parents []reflect.Type) bool {
+	if typ.Kind() != reflect.Ptr {
+		return false
+	}
+	for _, parent := range parents {
+		if parent == typ.Elem() {
+			return true
 		}
 	}
+	return false
+}
+
+func appendIndex(index []int, i int) []int {
+	result := make([]int, len(index), len(index)+1)
+	copy(result, index)
+	return append(result, i)
 }
 
 func getFieldName(field reflect.StructField) string {
@@ -214,3 +1526,18 @@ func getFieldName(field reflect.StructField) string {
 	}
 	return field.Name
 }
+
+// getGroupName returns the path segment used for a nested
+// struct: its configPrefix when present, its field name otherwise.
+func getGroupName(field reflect.StructField, prefix string) string {
+	if len(prefix) > 0 {
+		return prefix
+	}
+	return getFieldName(field)
+}
+
+func appendPath(path []string, name string) []string {
+	result := make([]string, len(path), len(path)+1)
+	copy(result, path)
+	return append(result, name)
+}
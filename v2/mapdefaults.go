@@ -0,0 +1,76 @@
+package configloader
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WithDefaults sets default values from code, for defaults known only
+// at runtime, like a port that depends on the environment. Keys name
+// fields as params do, or by their dotted path. The values are applied
+// before every other hook, so any hook overrides them, including
+// CreateDefaultsHook with the configDefault tags. Calling it again
+// adds to the previous values.
+func (loader *ConfigLoader) WithDefaults(values map[string]string) *ConfigLoader {
+	if loader.defaults == nil {
+		loader.defaults = make(map[string]string)
+		pending := loader.takeHooks()
+		loader.hooks.Enqueue(valuesDefaultsHook{})
+		for _, hook := range pending {
+			loader.hooks.Enqueue(hook)
+		}
+	}
+	for key, value := range values {
+		loader.defaults[key] = value
+	}
+	return loader
+}
+
+// valuesDefaultsHook applies the values passed to WithDefaults.
+type valuesDefaultsHook struct{}
+
+func (hook valuesDefaultsHook) source() string {
+	return "default"
+}
+
+func (hook valuesDefaultsHook) run(loader *ConfigLoader) error {
+	used := make(map[string]bool)
+	err := loader.foreachField(func(field currentField) error {
+		if key, value, ok := lookupFieldValue(loader.defaults, field); ok {
+			used[key] = true
+			return loader.setField(field, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if unknown := unusedKeys(loader.defaults, used); len(unknown) > 0 {
+		return fmt.Errorf("defaults for unknown fields: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// lookupFieldValue finds the value for field in values, keyed by the
+// field name, as used by params, or by its dotted path.
+func lookupFieldValue(values map[string]string, field currentField) (string, string, bool) {
+	for _, key := range []string{field.name, strings.Join(field.path, ".")} {
+		if value, ok := values[key]; ok {
+			return key, value, true
+		}
+	}
+	return "", "", false
+}
+
+// unusedKeys returns the sorted keys of values missing from used.
+func unusedKeys(values map[string]string, used map[string]bool) []string {
+	unknown := make([]string, 0)
+	for key := range values {
+		if !used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
@@ -0,0 +1,107 @@
+package configloader
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WithFieldMapping renames fields for every hook that finds fields by
+// name, from a mapping of external keys to fields, kept apart from the
+// struct tags. Fields are named as params do, or by their dotted path.
+// With
+//
+//	loader.WithFieldMapping(map[string]string{"db_host": "Database.Host"})
+//
+// the field is read from CONFIG_DB_HOST, -db_host, the db_host key of
+// key value sources and so on, instead of its own name. Keys with dots
+// are paths in flat JSON files. Hooks decoding whole JSON documents
+// keep using the JSON names. OnFieldSet and Results use the keys too.
+// Mapping an unknown field, or the same field from two keys, is an
+// error. Calling it again adds to the previous mapping.
+func (loader *ConfigLoader) WithFieldMapping(mapping map[string]string) *ConfigLoader {
+	if loader.mapping == nil {
+		loader.mapping = make(map[string][]string)
+	}
+	for key, field := range mapping {
+		loader.mapping[field] = appendKey(loader.mapping[field], key)
+	}
+	return loader
+}
+
+// appendKey adds key to keys, keeping them sorted and without
+// repeats, so errors list them in a stable order.
+func appendKey(keys []string, key string) []string {
+	for _, existing := range keys {
+		if existing == key {
+			return keys
+		}
+	}
+	keys = append(keys, key)
+	sort.Strings(keys)
+	return keys
+}
+
+// mapField renames field as WithFieldMapping says, if it is mapped.
+func (loader *ConfigLoader) mapField(field currentField) currentField {
+	if len(loader.mapping) == 0 {
+		return field
+	}
+	keys, ok := loader.mapping[field.id()]
+	if !ok {
+		keys, ok = loader.mapping[field.name]
+	}
+	if ok {
+		key := keys[0]
+		field.name = key
+		field.path = strings.Split(key, ".")
+		field.prefixes = nil
+	}
+	return field
+}
+
+// checkMapping returns an error if some field in the mapping does not
+// exist in the target, or if a field is mapped from more than one key,
+// either directly or through both its name and its path.
+func (loader *ConfigLoader) checkMapping() error {
+	if len(loader.mapping) == 0 {
+		return nil
+	}
+	ids := make(map[string]string)
+	markUsed := func(field currentField) error {
+		ids[field.id()] = field.id()
+		ids[field.name] = field.id()
+		return nil
+	}
+	foreachField(loader.target, markUsed)
+	if loader.setters {
+		foreachSetterField(loader.target, markUsed)
+	}
+	unknown := make([]string, 0)
+	keys := make(map[string][]string)
+	for field, fieldKeys := range loader.mapping {
+		id, ok := ids[field]
+		if !ok {
+			unknown = append(unknown, field)
+			continue
+		}
+		for _, key := range fieldKeys {
+			keys[id] = appendKey(keys[id], key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("field mapping for unknown fields: %s", strings.Join(unknown, ", "))
+	}
+	duplicated := make([]string, 0)
+	for id, fieldKeys := range keys {
+		if len(fieldKeys) > 1 {
+			duplicated = append(duplicated, fmt.Sprintf("%s (%s)", id, strings.Join(fieldKeys, ", ")))
+		}
+	}
+	if len(duplicated) > 0 {
+		sort.Strings(duplicated)
+		return fmt.Errorf("field mapping with more than one key for: %s", strings.Join(duplicated, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package configloader
+
+import "testing"
+
+type mappingConfig struct {
+	Name     string
+	Database struct {
+		Host string
+	} `configPrefix:"db"`
+}
+
+func TestFieldMappingRenamesFields(t *testing.T) {
+	var config mappingConfig
+	_, err := NewConfigLoaderFor(&config).
+		WithFieldMapping(map[string]string{"db_host": "db.Host", "app": "Name"}).
+		AddHook(CreateTestHook(map[string]string{"db_host": "localhost", "app": "api", "Name": "ignored"})).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "api" || config.Database.Host != "localhost" {
+		t.Errorf("unexpected config %+v", config)
+	}
+}
+
+func TestFieldMappingRejectsUnknownFields(t *testing.T) {
+	var config mappingConfig
+	_, err := NewConfigLoaderFor(&config).
+		WithFieldMapping(map[string]string{"port": "Port", "host": "db.Port"}).
+		SafeRetrieve()
+	if err == nil || err.Error() != "field mapping for unknown fields: Port, db.Port" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestFieldMappingRejectsFieldsMappedTwice(t *testing.T) {
+	cases := []struct {
+		mappings []map[string]string
+		expected string
+	}{
+		{
+			[]map[string]string{{"db_host": "db.Host", "host": "db.Host"}},
+			"field mapping with more than one key for: db.Host (db_host, host)",
+		},
+		{
+			[]map[string]string{{"db_host": "db.Host"}, {"host": "db.Host"}},
+			"field mapping with more than one key for: db.Host (db_host, host)",
+		},
+		{
+			[]map[string]string{{"db_host": "db.Host", "host": "dbHost", "app": "Name", "name": "Name"}},
+			"field mapping with more than one key for: Name (app, name); db.Host (db_host, host)",
+		},
+	}
+	for _, test := range cases {
+		var config mappingConfig
+		loader := NewConfigLoaderFor(&config)
+		for _, mapping := range test.mappings {
+			loader.WithFieldMapping(mapping)
+		}
+		if _, err := loader.SafeRetrieve(); err == nil || err.Error() != test.expected {
+			t.Errorf("%v: expected %q, got %v", test.mappings, test.expected, err)
+		}
+	}
+}
+
+func TestFieldMappingAcceptsRepeatedKey(t *testing.T) {
+	var config mappingConfig
+	_, err := NewConfigLoaderFor(&config).
+		WithFieldMapping(map[string]string{"db_host": "db.Host"}).
+		WithFieldMapping(map[string]string{"db_host": "db.Host"}).
+		AddHook(CreateTestHook(map[string]string{"db_host": "localhost"})).
+		SafeRetrieve()
+	if err != nil || config.Database.Host != "localhost" {
+		t.Errorf("unexpected config %+v (%v)", config, err)
+	}
+}
@@ -0,0 +1,53 @@
+package configloader
+
+import "reflect"
+
+// WithEmptyClears makes empty slices and maps from a source replace
+// the values set by earlier hooks. By default they are treated as
+// missing values, so an empty list in an env var or a file does not
+// wipe a list loaded before.
+func (loader *ConfigLoader) WithEmptyClears() *ConfigLoader {
+	loader.emptyClears = true
+	return loader
+}
+
+func isEmptyList(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return false
+}
+
+// keepList saves the value of a slice or map field before a hook sets
+// it. The returned func puts it back if the hook left it empty, and
+// reports whether it did, so the field does not count as set.
+func (loader *ConfigLoader) keepList(field currentField) func() bool {
+	if loader.emptyClears || isEmptyList(field.value) || (field.value.Kind() != reflect.Slice && field.value.Kind() != reflect.Map) {
+		return func() bool { return false }
+	}
+	saved := reflect.New(field.value.Type()).Elem()
+	saved.Set(field.value)
+	return func() bool {
+		if !isEmptyList(field.value) {
+			return false
+		}
+		field.value.Set(saved)
+		return true
+	}
+}
+
+// keepLists works like keepList for every field of the target, for
+// hooks decoding the whole target at once.
+func (loader *ConfigLoader) keepLists() func() {
+	restores := make([]func() bool, 0)
+	foreachField(loader.target, func(field currentField) error {
+		restores = append(restores, loader.keepList(field))
+		return nil
+	})
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package configloader
+
+import (
+	"strings"
+	"unicode"
+)
+
+// KebabCase is a NamingStrategy writing field names in lowercase words
+// joined by hyphens, like "db-port" for DBPort or dbPort, for sources
+// with hyphenated keys, like files mounted by container platforms.
+func KebabCase(name string) string {
+	return strings.Join(splitWords(name), "-")
+}
+
+// SnakeCase is a NamingStrategy writing field names in lowercase words
+// joined by underscores, like "db_port" for DBPort or dbPort.
+func SnakeCase(name string) string {
+	return strings.Join(splitWords(name), "_")
+}
+
+// splitWords splits a field name into lowercase words. A word starts at
+// each uppercase letter after a lowercase letter or a digit, and at the
+// last uppercase letter of an acronym followed by a lowercase one, so
+// HTTPServer gives "http" and "server". Underscores, hyphens, dots and
+// spaces separate words too.
+func splitWords(name string) []string {
+	words := make([]string, 0)
+	runes := []rune(name)
+	start := 0
+	flush := func(end int) {
+		if end > start {
+			words = append(words, strings.ToLower(string(runes[start:end])))
+		}
+	}
+	for i, current := range runes {
+		if current == '_' || current == '-' || current == '.' || current == ' ' {
+			flush(i)
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(current) {
+			continue
+		}
+		previous := runes[i-1]
+		nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(runes))
+	return words
+}
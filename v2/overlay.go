@@ -0,0 +1,101 @@
+package configloader
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OverlayHook copies the set fields of a partial config into the
+// target, for overrides computed in code and for tests.
+type OverlayHook struct {
+	partial interface{}
+}
+
+// CreateOverlayHook creates a hook which overlays partial, a value
+// of the target type or a pointer to one, onto the target. Only the
+// fields of partial which are set are copied:
+//
+//   - bools are set when true, so an overlay can never turn a bool
+//     off. Use a *bool field for that.
+//   - numbers are set when they are not 0, and strings when they are
+//     not empty.
+//   - slices and maps are set when they have items, like empty lists
+//     in other sources (see WithEmptyClears).
+//   - pointers are set when they are not nil, even if they point to a
+//     zero value.
+//   - structs used as values, like time.Time, are set when they are
+//     not their zero value.
+func CreateOverlayHook(partial interface{}) OverlayHook {
+	return OverlayHook{partial: partial}
+}
+
+func (hook OverlayHook) source() string {
+	return "overlay"
+}
+
+func (hook OverlayHook) run(loader *ConfigLoader) error {
+	return overlayFields(loader.target, hook.partial, func(field currentField, value reflect.Value) error {
+		if !allowsSource(field, loader.source) || loader.isSkipped(field) {
+			return nil
+		}
+		field.value.Set(deepCopy(value))
+		return loader.done(field, nil)
+	})
+}
+
+// Merge copies the set fields of override into base, which must be a
+// pointer to a struct. override is a value of the same type or a
+// pointer to one. Fields count as set like in CreateOverlayHook, and
+// nested structs are merged field by field. It does not need a loader,
+// so it is handy to compose configs built in different places.
+func Merge(base, override interface{}) error {
+	return overlayFields(base, override, func(field currentField, value reflect.Value) error {
+		field.value.Set(deepCopy(value))
+		if field.commit != nil {
+			field.commit()
+		}
+		return nil
+	})
+}
+
+// overlayFields calls set for each field of target which is set in
+// partial, passing its value in partial.
+func overlayFields(target, partial interface{}, set func(currentField, reflect.Value) error) error {
+	value := reflect.ValueOf(partial)
+	if !value.IsValid() {
+		return nil
+	}
+	if value.Kind() != reflect.Ptr {
+		copied := reflect.New(value.Type())
+		copied.Elem().Set(value)
+		value = copied
+	}
+	if typ := reflect.TypeOf(target); value.Type() != typ || typ.Kind() != reflect.Ptr {
+		return fmt.Errorf("overlay of type %s does not match the target type %s", value.Type(), typ)
+	}
+	if value.IsNil() {
+		return nil
+	}
+	values := make([]reflect.Value, 0)
+	foreachField(value.Interface(), func(field currentField) error {
+		values = append(values, field.value)
+		return nil
+	})
+	position := 0
+	return foreachField(target, func(field currentField) error {
+		current := values[position]
+		position++
+		if isOverlayZero(current) {
+			return nil
+		}
+		return set(field, current)
+	})
+}
+
+func isOverlayZero(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
@@ -0,0 +1,51 @@
+package configloader
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ApplyPartial sets only the fields named in values, leaving the rest
+// of the target untouched, for remote sources which send just the
+// keys that changed. Keys name fields as in WithDefaults: like params
+// do, or by their dotted path. It returns the names of the fields
+// whose value changed. Values are set as the "partial" source, so
+// configSource, WithSkipFields and OnFieldSet apply, and the whole
+// target is validated afterwards. Unknown keys are an error, checked
+// before any field is set. A value which does not parse is an error
+// too, but the fields set before it keep their new value.
+func (loader *ConfigLoader) ApplyPartial(values map[string]string) ([]string, error) {
+	used := make(map[string]bool)
+	foreachField(loader.target, func(field currentField) error {
+		if key, _, ok := lookupFieldValue(values, loader.mapField(field)); ok {
+			used[key] = true
+		}
+		return nil
+	})
+	if unknown := unusedKeys(values, used); len(unknown) > 0 {
+		return nil, fmt.Errorf("values for unknown fields: %s", strings.Join(unknown, ", "))
+	}
+	loader.source = "partial"
+	loader.failedField = ""
+	defer func() { loader.source = "" }()
+	changed := make([]string, 0)
+	err := loader.foreachField(func(field currentField) error {
+		_, value, ok := lookupFieldValue(values, field)
+		if !ok {
+			return nil
+		}
+		before := deepCopy(field.value)
+		if err := loader.setField(field, value); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(before.Interface(), field.value.Interface()) {
+			changed = append(changed, field.name)
+		}
+		return nil
+	})
+	if err != nil {
+		return changed, err
+	}
+	return changed, loader.validate()
+}
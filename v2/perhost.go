@@ -0,0 +1,81 @@
+package configloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PerHostFileHook will load a JSON file shared by a fleet, with a
+// default block and overrides for some hosts.
+type PerHostFileHook struct {
+	file string
+}
+
+// CreatePerHostFileHook creates a hook which loads file, a JSON file
+// like
+//
+//	{
+//	    "default": {"Workers": 4, "LogLevel": "info"},
+//	    "hosts": {
+//	        "web1": {"Workers": 16}
+//	    }
+//	}
+//
+// The "default" block is loaded first, then the block under "hosts"
+// named like the machine (as returned by os.Hostname, ignoring case)
+// on top, so it only needs the keys that differ. Hosts without a block
+// just get the defaults.
+func CreatePerHostFileHook(file string) PerHostFileHook {
+	return PerHostFileHook{file: file}
+}
+
+func (hook PerHostFileHook) source() string {
+	return "file"
+}
+
+func (hook PerHostFileHook) run(loader *ConfigLoader) error {
+	data, err := os.ReadFile(hook.file)
+	if err != nil {
+		return fmt.Errorf("error while reading config file: %w", err)
+	}
+	var document struct {
+		Default json.RawMessage            `json:"default"`
+		Hosts   map[string]json.RawMessage `json:"hosts"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("error while decoding config file: %w", err)
+	}
+	if len(document.Default) > 0 {
+		if err := loader.decodeJSON(document.Default, false); err != nil {
+			return fmt.Errorf("error while decoding default block of config file: %w", err)
+		}
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("error while reading the hostname: %w", err)
+	}
+	host, ok := findHostBlock(document.Hosts, hostname)
+	if !ok {
+		return nil
+	}
+	if err := loader.decodeJSON(document.Hosts[host], false); err != nil {
+		return fmt.Errorf("error while decoding block of host '%s' of config file: %w", host, err)
+	}
+	return nil
+}
+
+// findHostBlock returns the key of hosts for hostname, preferring an
+// exact match but ignoring case otherwise, since hostnames do.
+func findHostBlock(hosts map[string]json.RawMessage, hostname string) (string, bool) {
+	if _, ok := hosts[hostname]; ok {
+		return hostname, true
+	}
+	for host := range hosts {
+		if strings.EqualFold(host, hostname) {
+			return host, true
+		}
+	}
+	return "", false
+}
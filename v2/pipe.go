@@ -0,0 +1,105 @@
+package configloader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// PipeHook will load data from JSON written to a named pipe (FIFO).
+type PipeHook struct {
+	path    string
+	timeout time.Duration
+}
+
+// CreatePipeHook creates a hook which opens the pipe at path, reads
+// it until the writer closes it and decodes the JSON it got, like
+// CreateFileHook. Opening a pipe blocks until something opens it for
+// writing, so by default the hook waits for as long as it takes. Use
+// WithTimeout to give up instead.
+func CreatePipeHook(path string) PipeHook {
+	return PipeHook{path: path}
+}
+
+// WithTimeout makes the hook fail if no writer opens the pipe and
+// closes it within timeout, so startup never hangs forever.
+func (hook PipeHook) WithTimeout(timeout time.Duration) PipeHook {
+	hook.timeout = timeout
+	return hook
+}
+
+func (hook PipeHook) source() string {
+	return "pipe"
+}
+
+func (hook PipeHook) run(loader *ConfigLoader) error {
+	deadline := time.Now().Add(hook.timeout)
+	file, err := hook.open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if hook.timeout > 0 {
+		// Pipes support deadlines on most systems. Where they don't,
+		// only opening the pipe is limited by the timeout.
+		file.SetReadDeadline(deadline)
+	}
+	data, err := io.ReadAll(file)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return fmt.Errorf("timed out after %s reading config pipe %s", hook.timeout, hook.path)
+	}
+	if err != nil {
+		return fmt.Errorf("error while reading config pipe: %w", err)
+	}
+	if err := loader.decodeJSON(data, false); err != nil {
+		return fmt.Errorf("error while decoding config pipe: %w", err)
+	}
+	return nil
+}
+
+type openedPipe struct {
+	file *os.File
+	err  error
+}
+
+// open opens the pipe for reading, waiting at most the hook timeout
+// for a writer.
+func (hook PipeHook) open() (*os.File, error) {
+	if hook.timeout <= 0 {
+		return hook.openNow()
+	}
+	opened := make(chan openedPipe, 1)
+	go func() {
+		file, err := hook.openNow()
+		opened <- openedPipe{file: file, err: err}
+	}()
+	select {
+	case result := <-opened:
+		return result.file, result.err
+	case <-time.After(hook.timeout):
+		// The goroutine is still blocked opening the pipe. Opening
+		// the other end unblocks it, and the file is closed once it
+		// is open. The open must not block itself: if the reader is
+		// already gone, it fails with ENXIO, which is fine.
+		if writer, err := os.OpenFile(hook.path, os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+			writer.Close()
+		}
+		go func() {
+			if result := <-opened; result.file != nil {
+				result.file.Close()
+			}
+		}()
+		return nil, fmt.Errorf("timed out after %s waiting for a writer on config pipe %s", hook.timeout, hook.path)
+	}
+}
+
+func (hook PipeHook) openNow() (*os.File, error) {
+	file, err := os.Open(hook.path)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening config pipe: %w", err)
+	}
+	return file, nil
+}
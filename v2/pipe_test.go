@@ -0,0 +1,57 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package configloader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func makeTestFifo(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.fifo")
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		t.Skipf("can't create a FIFO: %v", err)
+	}
+	return path
+}
+
+func TestPipeHookReadsWriter(t *testing.T) {
+	path := makeTestFifo(t)
+	go func() {
+		writer, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		writer.WriteString(`{"Name": "piped"}`)
+		writer.Close()
+	}()
+	var config struct{ Name string }
+	_, err := NewConfigLoaderFor(&config).AddHook(CreatePipeHook(path).WithTimeout(5 * time.Second)).SafeRetrieve()
+	if err != nil || config.Name != "piped" {
+		t.Fatalf("expected piped, got %q (%v)", config.Name, err)
+	}
+}
+
+func TestPipeHookTimesOutWithoutWriter(t *testing.T) {
+	path := makeTestFifo(t)
+	var config struct{ Name string }
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewConfigLoaderFor(&config).AddHook(CreatePipeHook(path).WithTimeout(50 * time.Millisecond)).SafeRetrieve()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Fatalf("expected a timeout, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the hook hung after its timeout")
+	}
+}
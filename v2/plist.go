@@ -0,0 +1,184 @@
+package configloader
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PlistHook will load data from an Apple property list file, in
+// XML or binary format.
+type PlistHook struct {
+	file string
+}
+
+// CreatePlistHook creates a hook which decodes a plist file into the
+// target, like CreateFileHook does with JSON: dict keys match the
+// JSON name of each field, ignoring case. Dates are read as RFC 3339
+// times and data as []byte fields.
+func CreatePlistHook(file string) PlistHook {
+	return PlistHook{file: file}
+}
+
+func (hook PlistHook) source() string {
+	return "file"
+}
+
+func (hook PlistHook) run(loader *ConfigLoader) error {
+	data, err := os.ReadFile(hook.file)
+	if err != nil {
+		return fmt.Errorf("error while reading plist file: %w", err)
+	}
+	document, err := decodePlist(data)
+	if err != nil {
+		return fmt.Errorf("error while decoding plist file: %w", err)
+	}
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("error while decoding plist file: %w", err)
+	}
+	return loader.decodeJSON(encoded, false)
+}
+
+// decodePlist decodes a plist to the values encoding/json would
+// produce for the same document, so it can be encoded as JSON.
+func decodePlist(data []byte) (interface{}, error) {
+	if bytes.HasPrefix(data, []byte(binaryPlistMagic)) {
+		return decodeBinaryPlist(data)
+	}
+	return decodeXMLPlist(data)
+}
+
+func decodeXMLPlist(data []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no plist element found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "plist" {
+			return decodeXMLPlistValue(decoder, start)
+		}
+		value, err := nextXMLPlistValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+// nextXMLPlistValue decodes the next element, or returns nil if the
+// parent element ends first.
+func nextXMLPlistValue(decoder *xml.Decoder) (interface{}, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			return decodeXMLPlistValue(decoder, element)
+		case xml.EndElement:
+			return nil, nil
+		}
+	}
+}
+
+func decodeXMLPlistValue(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		return decodeXMLPlistDict(decoder)
+	case "array":
+		return decodeXMLPlistArray(decoder)
+	case "true", "false":
+		if err := decoder.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+	var text string
+	if err := decoder.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	switch start.Name.Local {
+	case "string", "date":
+		return text, nil
+	case "integer":
+		if _, err := strconv.ParseInt(text, 10, 64); err != nil {
+			if _, err := strconv.ParseUint(text, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid integer '%s'", text)
+			}
+		}
+		return json.Number(text), nil
+	case "real":
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			return nil, fmt.Errorf("invalid real '%s'", text)
+		}
+		return json.Number(text), nil
+	case "data":
+		compact := strings.Join(strings.Fields(text), "")
+		if _, err := base64.StdEncoding.DecodeString(compact); err != nil {
+			return nil, fmt.Errorf("invalid data: %w", err)
+		}
+		return compact, nil
+	}
+	return nil, fmt.Errorf("unsupported plist element <%s>", start.Name.Local)
+}
+
+func decodeXMLPlistDict(decoder *xml.Decoder) (interface{}, error) {
+	dict := make(map[string]interface{})
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch element := token.(type) {
+		case xml.EndElement:
+			return dict, nil
+		case xml.StartElement:
+			if element.Name.Local != "key" {
+				return nil, fmt.Errorf("expected <key> in <dict>, got <%s>", element.Name.Local)
+			}
+			var key string
+			if err := decoder.DecodeElement(&key, &element); err != nil {
+				return nil, err
+			}
+			value, err := nextXMLPlistValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			if value == nil {
+				return nil, fmt.Errorf("missing value for key '%s'", key)
+			}
+			dict[key] = value
+		}
+	}
+}
+
+func decodeXMLPlistArray(decoder *xml.Decoder) (interface{}, error) {
+	array := make([]interface{}, 0)
+	for {
+		value, err := nextXMLPlistValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return array, nil
+		}
+		array = append(array, value)
+	}
+}
@@ -0,0 +1,86 @@
+package configloader
+
+import "testing"
+
+type pointerLeaf struct {
+	Limit *int
+	Name  string
+}
+
+type pointerBranch struct {
+	Leaf  *pointerLeaf `configPrefix:"leaf"`
+	Label *string
+}
+
+type pointerConfig struct {
+	Primary   *pointerBranch `configPrefix:"primary"`
+	Secondary *pointerLeaf   `configPrefix:"secondary"`
+}
+
+func TestNestedPointersAllocatedOnlyForSetLeaves(t *testing.T) {
+	var config pointerConfig
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(CreateTestHook(map[string]string{"leafLimit": "5"})).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Primary == nil || config.Primary.Leaf == nil || config.Primary.Leaf.Limit == nil {
+		t.Fatalf("expected the chain to the leaf to be allocated, got %+v", config.Primary)
+	}
+	if *config.Primary.Leaf.Limit != 5 {
+		t.Errorf("expected Limit 5, got %d", *config.Primary.Leaf.Limit)
+	}
+	if config.Primary.Label != nil {
+		t.Errorf("expected Label to stay nil, got %q", *config.Primary.Label)
+	}
+	if config.Secondary != nil {
+		t.Errorf("expected Secondary to stay nil, got %+v", config.Secondary)
+	}
+}
+
+func TestNestedPointersStayNilWithoutValues(t *testing.T) {
+	var config pointerConfig
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(CreateTestHook(map[string]string{"Unknown": "1"})).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Primary != nil || config.Secondary != nil {
+		t.Errorf("expected every branch to stay nil, got %+v", config)
+	}
+}
+
+func TestNestedPointersKeepExistingBranches(t *testing.T) {
+	leaf := &pointerLeaf{Name: "existing"}
+	config := pointerConfig{Secondary: leaf}
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(CreateTestHook(map[string]string{"secondaryLimit": "3"})).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Secondary != leaf || leaf.Name != "existing" {
+		t.Errorf("expected the existing leaf to be updated in place, got %+v", config.Secondary)
+	}
+	if leaf.Limit == nil || *leaf.Limit != 3 {
+		t.Errorf("expected Limit 3, got %v", leaf.Limit)
+	}
+	if config.Primary != nil {
+		t.Errorf("expected Primary to stay nil, got %+v", config.Primary)
+	}
+}
+
+func TestNestedPointersStayNilOnError(t *testing.T) {
+	var config pointerConfig
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(CreateTestHook(map[string]string{"leafLimit": "x"})).
+		SafeRetrieve()
+	if err == nil {
+		t.Fatal("expected an error for an invalid Limit")
+	}
+	if config.Primary != nil {
+		t.Errorf("expected Primary to stay nil, got %+v", config.Primary)
+	}
+}
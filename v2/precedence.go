@@ -0,0 +1,69 @@
+package configloader
+
+import (
+	"reflect"
+	"strings"
+)
+
+// hasPrecedence reports whether some field of target has a
+// configPrecedence tag.
+func hasPrecedence(target interface{}) bool {
+	for _, meta := range getTypeFields(reflect.TypeOf(target).Elem()) {
+		if _, ok := meta.original.Tag.Lookup("configPrecedence"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// recordPrecedence keeps the value the running source set, for
+// fields with a configPrecedence tag.
+func (loader *ConfigLoader) recordPrecedence(field currentField) {
+	if _, ok := field.original.Tag.Lookup("configPrecedence"); !ok || len(loader.source) == 0 {
+		return
+	}
+	if loader.precedence == nil {
+		loader.precedence = make(map[string]map[string]reflect.Value)
+	}
+	key := field.id()
+	if loader.precedence[key] == nil {
+		loader.precedence[key] = make(map[string]reflect.Value)
+	}
+	loader.precedence[key][loader.source] = deepCopy(field.value)
+}
+
+// resolvePrecedence sets every field with a configPrecedence tag,
+// like configPrecedence:"file,env,default", to the value of the first
+// source in the tag which set it, whatever the order of the hooks.
+// If none of them did, the field keeps the value the hooks left.
+func (loader *ConfigLoader) resolvePrecedence() {
+	if len(loader.precedence) == 0 {
+		return
+	}
+	loader.current = -1
+	foreachField(loader.target, func(field currentField) error {
+		field = loader.prepareField(field)
+		values, ok := loader.precedence[field.id()]
+		if !ok {
+			return nil
+		}
+		for _, source := range strings.Split(field.original.Tag.Get("configPrecedence"), ",") {
+			source = strings.TrimSpace(source)
+			value, ok := values[source]
+			if !ok {
+				continue
+			}
+			if !reflect.DeepEqual(value.Interface(), field.value.Interface()) {
+				field.value.Set(value)
+				if field.commit != nil {
+					field.commit()
+				}
+				loader.source = source
+				loader.notify(field)
+			}
+			return nil
+		}
+		return nil
+	})
+	loader.precedence = nil
+}
@@ -0,0 +1,45 @@
+package configloader
+
+import "testing"
+
+type precedenceConfig struct {
+	Port int `configDefault:"8080" configPrecedence:"file,env,default"`
+}
+
+func loadPrecedence(t *testing.T, file string) precedenceConfig {
+	t.Helper()
+	var config precedenceConfig
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(CreateDefaultsHook()).
+		AddHook(CreateFileHook(file)).
+		AddHook(CreateEnvHook()).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestPrecedenceFileBeatsEnv(t *testing.T) {
+	setTestEnv(t, "CONFIG_PORT", "9000")
+	config := loadPrecedence(t, writeTestFile(t, "config.json", `{"Port": 7000}`))
+	if config.Port != 7000 {
+		t.Errorf("expected the file port 7000, got %d", config.Port)
+	}
+}
+
+func TestPrecedenceFileValueEqualToDefault(t *testing.T) {
+	setTestEnv(t, "CONFIG_PORT", "9000")
+	config := loadPrecedence(t, writeTestFile(t, "config.json", `{"Port": 8080}`))
+	if config.Port != 8080 {
+		t.Errorf("expected the file port 8080, got %d", config.Port)
+	}
+}
+
+func TestPrecedenceFallsBackWithoutFileKey(t *testing.T) {
+	setTestEnv(t, "CONFIG_PORT", "9000")
+	config := loadPrecedence(t, writeTestFile(t, "config.json", `{}`))
+	if config.Port != 9000 {
+		t.Errorf("expected the env port 9000, got %d", config.Port)
+	}
+}
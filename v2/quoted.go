@@ -0,0 +1,109 @@
+package configloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// unquoteJSONValues rewrites data so strings holding a valid number
+// or bool become real JSON numbers or bools wherever target expects
+// them. Other values are left as they are, so encoding/json still
+// reports any real type mismatch.
+func unquoteJSONValues(target interface{}, data []byte) ([]byte, error) {
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	document = rewriteJSONValue(reflect.TypeOf(target).Elem(), "", document, unquoteJSONValue)
+	return json.Marshal(document)
+}
+
+// rewriteJSONValue walks value, decoded from JSON into a field of type
+// typ, and replaces each value that is not a struct, list or map with
+// what rewrite returns for it. rewrite also gets the tag of the struct
+// field holding the value, which items of lists and maps share.
+func rewriteJSONValue(typ reflect.Type, tag reflect.StructTag, value interface{}, rewrite func(reflect.Type, reflect.StructTag, interface{}) interface{}) interface{} {
+	if isValueStruct(typ) {
+		return rewrite(typ, tag, value)
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return rewriteJSONValue(typ.Elem(), tag, value, rewrite)
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if len(field.PkgPath) > 0 {
+				continue
+			}
+			name := getJSONName(field)
+			for key, current := range object {
+				if key == name || (!hasExactKey(object, name) && strings.EqualFold(key, name)) {
+					object[key] = rewriteJSONValue(field.Type, field.Tag, current, rewrite)
+				}
+			}
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		list, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+		for i, item := range list {
+			list[i] = rewriteJSONValue(typ.Elem(), tag, item, rewrite)
+		}
+		return list
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		for key, item := range object {
+			object[key] = rewriteJSONValue(typ.Elem(), tag, item, rewrite)
+		}
+		return object
+	default:
+		return rewrite(typ, tag, value)
+	}
+}
+
+func unquoteJSONValue(typ reflect.Type, _ reflect.StructTag, value interface{}) interface{} {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		text, ok := value.(string)
+		if !ok {
+			return value
+		}
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			return value
+		}
+		return json.Number(text)
+	case reflect.Bool:
+		text, ok := value.(string)
+		if !ok {
+			return value
+		}
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return value
+		}
+		return parsed
+	default:
+		return value
+	}
+}
+
+func hasExactKey(object map[string]interface{}, key string) bool {
+	_, ok := object[key]
+	return ok
+}
@@ -0,0 +1,31 @@
+package configloader
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+var regexpType = reflect.TypeOf(regexp.Regexp{})
+
+// setRegexp compiles rawValue into *regexp.Regexp fields, and
+// regexp.Regexp ones. It reports false if value is of another type.
+func setRegexp(value reflect.Value, rawValue string) (bool, error) {
+	typ := value.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ != regexpType {
+		return false, nil
+	}
+	compiled, err := regexp.Compile(rawValue)
+	if err != nil {
+		return true, fmt.Errorf("value '%s' is not a valid regular expression: %w", rawValue, err)
+	}
+	if value.Kind() == reflect.Ptr {
+		value.Set(reflect.ValueOf(compiled))
+	} else {
+		value.Set(reflect.ValueOf(compiled).Elem())
+	}
+	return true, nil
+}
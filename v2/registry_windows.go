@@ -0,0 +1,129 @@
+//go:build windows
+// +build windows
+
+package configloader
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+	"unicode/utf16"
+)
+
+// RegistryHook loads data from the values of a Windows registry key.
+// Only string (REG_SZ, REG_EXPAND_SZ) and DWORD values are read.
+type RegistryHook struct {
+	root   string
+	path   string
+	naming NamingStrategy
+}
+
+// CreateRegistryHook creates a hook which reads the values under
+// path in the root key. Root can be the full name, like
+// HKEY_CURRENT_USER, or the short one, like HKCU. Value names are
+// the field names, unless you change them with WithNaming or the
+// loader WithNamingStrategy.
+func CreateRegistryHook(root, path string) RegistryHook {
+	return RegistryHook{root: root, path: path}
+}
+
+// WithNaming changes how field names map to value names, overriding
+// the loader naming strategy.
+func (hook RegistryHook) WithNaming(naming NamingStrategy) RegistryHook {
+	hook.naming = naming
+	return hook
+}
+
+var registryRoots = map[string]syscall.Handle{
+	"HKEY_CLASSES_ROOT":   syscall.HKEY_CLASSES_ROOT,
+	"HKCR":                syscall.HKEY_CLASSES_ROOT,
+	"HKEY_CURRENT_USER":   syscall.HKEY_CURRENT_USER,
+	"HKCU":                syscall.HKEY_CURRENT_USER,
+	"HKEY_LOCAL_MACHINE":  syscall.HKEY_LOCAL_MACHINE,
+	"HKLM":                syscall.HKEY_LOCAL_MACHINE,
+	"HKEY_USERS":          syscall.HKEY_USERS,
+	"HKU":                 syscall.HKEY_USERS,
+	"HKEY_CURRENT_CONFIG": syscall.HKEY_CURRENT_CONFIG,
+	"HKCC":                syscall.HKEY_CURRENT_CONFIG,
+}
+
+func (hook RegistryHook) source() string {
+	return "registry"
+}
+
+func (hook RegistryHook) run(loader *ConfigLoader) error {
+	root, ok := registryRoots[strings.ToUpper(hook.root)]
+	if !ok {
+		return fmt.Errorf("unknown registry root '%s'", hook.root)
+	}
+	path, err := syscall.UTF16PtrFromString(hook.path)
+	if err != nil {
+		return err
+	}
+	var key syscall.Handle
+	if err := syscall.RegOpenKeyEx(root, path, 0, syscall.KEY_READ, &key); err != nil {
+		return fmt.Errorf("error while opening registry key %s\\%s: %w", hook.root, hook.path, err)
+	}
+	defer syscall.RegCloseKey(key)
+	naming := loader.namingFor(hook.naming)
+	return loader.foreachField(func(field currentField) error {
+		value, ok, err := readRegistryValue(key, naming(field.name))
+		if err != nil {
+			return fmt.Errorf("error while reading registry value for field %s: %w", field.name, err)
+		}
+		if !ok {
+			return nil
+		}
+		return loader.setField(field, value)
+	})
+}
+
+// readRegistryValue returns the value as a string, and false if it
+// does not exist.
+func readRegistryValue(key syscall.Handle, name string) (string, bool, error) {
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return "", false, err
+	}
+	var valueType, size uint32
+	err = syscall.RegQueryValueEx(key, namePtr, nil, &valueType, nil, &size)
+	if err == syscall.ERROR_FILE_NOT_FOUND {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if size == 0 {
+		return "", true, nil
+	}
+	buffer := make([]byte, size)
+	if err := syscall.RegQueryValueEx(key, namePtr, nil, &valueType, &buffer[0], &size); err != nil {
+		return "", false, err
+	}
+	buffer = buffer[:size]
+	switch valueType {
+	case syscall.REG_SZ, syscall.REG_EXPAND_SZ:
+		return decodeUTF16(buffer), true, nil
+	case syscall.REG_DWORD:
+		if len(buffer) < 4 {
+			return "", false, fmt.Errorf("invalid DWORD value")
+		}
+		return strconv.FormatUint(uint64(binary.LittleEndian.Uint32(buffer)), 10), true, nil
+	default:
+		return "", false, fmt.Errorf("unsupported registry value type %d", valueType)
+	}
+}
+
+func decodeUTF16(buffer []byte) string {
+	chars := make([]uint16, 0, len(buffer)/2)
+	for i := 0; i+1 < len(buffer); i += 2 {
+		char := binary.LittleEndian.Uint16(buffer[i:])
+		if char == 0 {
+			break
+		}
+		chars = append(chars, char)
+	}
+	return string(utf16.Decode(chars))
+}
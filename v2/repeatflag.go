@@ -0,0 +1,27 @@
+package configloader
+
+import "strings"
+
+// repeatedFlag is a flag.Value for slice, array and map fields, which
+// keeps every value passed, so -tag a -tag b builds a list.
+type repeatedFlag struct {
+	values []string
+}
+
+func (flag *repeatedFlag) String() string {
+	if flag == nil {
+		return ""
+	}
+	return strings.Join(flag.values, ",")
+}
+
+func (flag *repeatedFlag) Set(value string) error {
+	flag.values = append(flag.values, value)
+	return nil
+}
+
+// join returns the values as one list separated by separator, so
+// each value can also hold several items, like -tag a,b -tag c.
+func (flag *repeatedFlag) join(separator string) string {
+	return strings.Join(flag.values, separator)
+}
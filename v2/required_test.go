@@ -0,0 +1,71 @@
+package configloader
+
+import (
+	"strings"
+	"testing"
+)
+
+type requiredConfig struct {
+	Port int
+	Name string
+}
+
+func TestRequiredHookFailsWithoutValues(t *testing.T) {
+	var config requiredConfig
+	loader := NewConfigLoaderFor(&config).AddHook(Required(CreateEnvHookWithPrefixes("REQUIRED_TEST_")))
+	_, err := loader.SafeRetrieve()
+	if err == nil || err.Error() != "required EnvHook (env) loaded no values" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if results := loader.Results(); len(results) != 1 || results[0].Hook != "RequiredHook" || results[0].Err == nil {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
+
+func TestRequiredHookPassesWithValues(t *testing.T) {
+	setTestEnv(t, "REQUIRED_TEST_PORT", "8080")
+	var config requiredConfig
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(Required(CreateEnvHookWithPrefixes("REQUIRED_TEST_"))).
+		SafeRetrieve()
+	if err != nil || config.Port != 8080 {
+		t.Errorf("unexpected config %+v (%v)", config, err)
+	}
+}
+
+func TestRequiredHookCountsUnchangedKeys(t *testing.T) {
+	file := writeTestFile(t, "config.json", `{"Port": 0}`)
+	var config requiredConfig
+	if _, err := NewConfigLoaderFor(&config).AddHook(Required(CreateFileHook(file))).SafeRetrieve(); err != nil {
+		t.Errorf("expected a key equal to the current value to count, got %v", err)
+	}
+}
+
+func TestRequiredHookKeepsErrors(t *testing.T) {
+	var config requiredConfig
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(Required(CreateFileHook("/does/not/exist.json"))).
+		SafeRetrieve()
+	if err == nil || !strings.Contains(err.Error(), "error while reading config file") {
+		t.Errorf("expected the file error, got %v", err)
+	}
+}
+
+func TestOptionalRequiredHookWarns(t *testing.T) {
+	var config requiredConfig
+	loader := NewConfigLoaderFor(&config).AddHook(Optional(Required(CreateEnvHookWithPrefixes("REQUIRED_TEST_"))))
+	if _, err := loader.SafeRetrieve(); err != nil {
+		t.Fatal(err)
+	}
+	if len(loader.Warnings()) != 1 {
+		t.Errorf("expected one warning, got %v", loader.Warnings())
+	}
+}
+
+func TestRequiredHookString(t *testing.T) {
+	var config requiredConfig
+	loader := NewConfigLoaderFor(&config).AddHook(Required(CreateEnvHook()))
+	if got := loader.String(); !strings.Contains(got, "1. Required EnvHook (env)") {
+		t.Errorf("unexpected description %q", got)
+	}
+}
@@ -0,0 +1,35 @@
+package configloader
+
+import "reflect"
+
+// Reset prepares the loader to load the target again from scratch,
+// for reloads. It sets every field of the target to its zero value,
+// applies the configDefault tags again and queues the hooks that
+// already ran, so the next Retrieve runs them in the same order. This
+// way keys removed from a source go back to their defaults instead of
+// keeping stale values, since JSON files only overwrite the keys they
+// have. Fields excluded with WithSkipFields are kept. Warnings and
+// results of the previous load are cleared.
+func (loader *ConfigLoader) Reset() error {
+	foreachField(loader.target, func(field currentField) error {
+		if !loader.isSkipped(field) {
+			field.value.Set(reflect.Zero(field.value.Type()))
+		}
+		return nil
+	})
+	pending := loader.takeHooks()
+	for _, hook := range append(loader.ran, pending...) {
+		loader.hooks.Enqueue(hook)
+	}
+	loader.ran = nil
+	loader.warnings = nil
+	loader.results = nil
+	loader.deferred = nil
+	loader.precedence = nil
+	loader.written = nil
+	defaults := CreateDefaultsHook()
+	loader.source = defaults.source()
+	err := defaults.run(loader)
+	loader.source = ""
+	return err
+}
@@ -0,0 +1,35 @@
+package configloader
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+func TestResetReloadsParams(t *testing.T) {
+	args := os.Args
+	os.Args = []string{"app", "-Port", "8080", "-Tags", "a", "-Tags", "b"}
+	t.Cleanup(func() { os.Args = args })
+	var config struct {
+		Port int
+		Tags []string
+	}
+	loader := NewConfigLoaderFor(&config).
+		AddHook(CreateParamsHookWithErrorHandling(flag.ContinueOnError))
+	if _, err := loader.SafeRetrieve(); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if config.Port != 0 {
+		t.Fatalf("expected Reset to clear Port, got %d", config.Port)
+	}
+	os.Args = []string{"app", "-Tags", "c"}
+	if _, err := loader.SafeRetrieve(); err != nil {
+		t.Fatal(err)
+	}
+	if config.Port != 0 || len(config.Tags) != 1 || config.Tags[0] != "c" {
+		t.Errorf("expected only the new params, got %+v", config)
+	}
+}
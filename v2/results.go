@@ -0,0 +1,170 @@
+package configloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// HookResult summarizes what a hook did while loading.
+type HookResult struct {
+	// Hook is the type of the hook, like "EnvHook".
+	Hook string
+	// Source is the name used by configSource, like "env".
+	Source string
+	// Layer is the name of a file hook created with
+	// CreateFileHookNamed, or "" for other hooks.
+	Layer string
+	// Fields are the names of the fields the hook set, in order.
+	// For JSON documents, those are the fields with a key in them.
+	Fields []string
+	// Warnings are the errors that did not stop loading, like the
+	// values skipped in lenient mode or the error of an Optional hook.
+	Warnings []error
+	// Err is the error that stopped loading, if this hook failed.
+	Err error
+}
+
+// Results returns a summary of each hook that ran, in order. Hooks
+// after a failing one did not run, so they are missing.
+func (loader *ConfigLoader) Results() []HookResult {
+	results := make([]HookResult, len(loader.results))
+	copy(results, loader.results)
+	return results
+}
+
+func hookName(hook Hook) string {
+	return reflect.TypeOf(hook).Name()
+}
+
+// startResult adds a result for hook, which receives the fields it
+// sets until finishResult is called.
+func (loader *ConfigLoader) startResult(hook Hook) {
+	loader.results = append(loader.results, HookResult{
+		Hook:   hookName(hook),
+		Source: hook.source(),
+		Layer:  hookLayer(hook),
+		Fields: make([]string, 0),
+	})
+	loader.current = len(loader.results) - 1
+	loader.warningsBefore = len(loader.warnings)
+}
+
+func (loader *ConfigLoader) finishResult(err error) {
+	if loader.current < 0 || loader.current >= len(loader.results) {
+		return
+	}
+	result := &loader.results[loader.current]
+	result.Warnings = append(result.Warnings, loader.warnings[loader.warningsBefore:]...)
+	if err != nil {
+		result.Err = err
+	}
+	loader.current = -1
+}
+
+// resultFields returns how many fields the running hook has set.
+func (loader *ConfigLoader) resultFields() int {
+	if loader.current < 0 || loader.current >= len(loader.results) {
+		return 0
+	}
+	return len(loader.results[loader.current].Fields)
+}
+
+// recordField adds a set field to the result of the running hook.
+func (loader *ConfigLoader) recordField(field currentField) {
+	if loader.current < 0 || loader.current >= len(loader.results) {
+		return
+	}
+	result := &loader.results[loader.current]
+	for _, name := range result.Fields {
+		if name == field.name {
+			return
+		}
+	}
+	result.Fields = append(result.Fields, field.name)
+}
+
+// recordJSONKeys records as set, in the result of the running hook,
+// for WithNoImplicitZeros and for configPrecedence, every field it may
+// set whose key is in data, a JSON document decoded into the target.
+// Those fields count as set even if decoding did not change them, like
+// a zero written over a zero, and finding them needs no copy of the
+// target.
+func (loader *ConfigLoader) recordJSONKeys(data []byte) {
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return
+	}
+	target := reflect.ValueOf(loader.target).Elem()
+	typ := target.Type()
+	for _, meta := range getTypeFields(typ) {
+		if len(meta.original.PkgPath) > 0 || !hasJSONKey(typ, meta.index, document) {
+			continue
+		}
+		value, commit := fieldByIndex(target, meta.index)
+		if commit != nil {
+			continue
+		}
+		field := loader.prepareField(currentField{
+			original: meta.original,
+			value:    value,
+			name:     meta.name,
+			path:     meta.path,
+			prefixes: meta.prefixes,
+			origin:   meta.path,
+		})
+		if !allowsSource(field, loader.source) || loader.isSkipped(field) {
+			continue
+		}
+		loader.recordField(field)
+		loader.recordPrecedence(field)
+		loader.recordWritten(field)
+	}
+}
+
+// hasJSONKey reports whether document has a key for the field of typ
+// at index, following the JSON names of the structs on the way.
+func hasJSONKey(typ reflect.Type, index []int, document interface{}) bool {
+	for _, position := range index {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		field := typ.Field(position)
+		typ = field.Type
+		if field.Anonymous && len(strings.Split(field.Tag.Get("json"), ",")[0]) == 0 {
+			// encoding/json promotes the fields of embedded structs.
+			continue
+		}
+		object, ok := document.(map[string]interface{})
+		if !ok || field.Tag.Get("json") == "-" {
+			return false
+		}
+		key := findJSONKey(object, getJSONName(field))
+		if len(key) == 0 {
+			return false
+		}
+		document = object[key]
+	}
+	return true
+}
+
+// deferredAction is work a hook leaves for after every hook ran,
+// like resolving defaults that reference other fields.
+type deferredAction struct {
+	result int
+	source string
+	run    func() error
+}
+
+// deferAction runs action once every hook ran, on behalf of the
+// running hook.
+func (loader *ConfigLoader) deferAction(action func() error) {
+	loader.deferred = append(loader.deferred, deferredAction{
+		result: loader.current,
+		source: loader.source,
+		run:    action,
+	})
+}
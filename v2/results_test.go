@@ -0,0 +1,69 @@
+package configloader
+
+import (
+	"reflect"
+	"testing"
+)
+
+type resultsConfig struct {
+	Name    string
+	Port    int
+	Retries int
+}
+
+func TestResultsListFieldsPerHook(t *testing.T) {
+	file := writeTestFile(t, "config.json", `{"Name": "x", "Retries": 0}`)
+	setTestEnv(t, "CONFIG_PORT", "8080")
+	var config resultsConfig
+	loader := NewConfigLoaderFor(&config).
+		AddHook(CreateFileHook(file)).
+		AddHook(CreateEnvHook())
+	if _, err := loader.SafeRetrieve(); err != nil {
+		t.Fatal(err)
+	}
+	results := loader.Results()
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+	if results[0].Hook != "ConfigFileHook" || results[0].Source != "file" || !reflect.DeepEqual(results[0].Fields, []string{"Name", "Retries"}) {
+		t.Errorf("unexpected file result %+v", results[0])
+	}
+	if results[1].Hook != "EnvHook" || !reflect.DeepEqual(results[1].Fields, []string{"Port"}) {
+		t.Errorf("unexpected env result %+v", results[1])
+	}
+}
+
+func TestResultsRecordWarningsAndErrors(t *testing.T) {
+	setTestEnv(t, "CONFIG_PORT", "nope")
+	var config resultsConfig
+	loader := NewConfigLoaderFor(&config).
+		AddHook(Optional(CreateFileHook("/does/not/exist.json"))).
+		AddHook(CreateEnvHook())
+	if _, err := loader.SafeRetrieve(); err == nil {
+		t.Fatal("expected the env hook to fail")
+	}
+	results := loader.Results()
+	if len(results) != 2 || len(results[0].Warnings) != 1 || results[0].Err != nil || results[1].Err == nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
+
+func TestFileHookNotifiesChangedFields(t *testing.T) {
+	file := writeTestFile(t, "config.json", `{"Name": "x", "Port": 0}`)
+	var config resultsConfig
+	changed := make([]string, 0)
+	record := func(name string, value interface{}) { changed = append(changed, name) }
+	loader := NewConfigLoaderFor(&config).
+		OnFieldSet("Name", record).
+		OnFieldSet("Port", record).
+		AddHook(CreateFileHook(file))
+	if _, err := loader.SafeRetrieve(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(changed, []string{"Name"}) {
+		t.Fatalf("expected only Name to be notified, got %v", changed)
+	}
+	if !reflect.DeepEqual(loader.Results()[0].Fields, []string{"Name", "Port"}) {
+		t.Fatalf("expected both keys in the result, got %v", loader.Results()[0].Fields)
+	}
+}
@@ -0,0 +1,41 @@
+package configloader
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+const maxPanicFrames = 10
+
+// SafeRetrieve works like TryRetrieve, but also turns panics while
+// loading into errors, like the ones caused by struct definitions the
+// loader does not expect. The error holds the recovered value and the
+// functions that were running when it panicked.
+func (loader *ConfigLoader) SafeRetrieve() (target interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			target = nil
+			err = fmt.Errorf("panic while loading config: %v\n%s", recovered, panicStack())
+		}
+	}()
+	return loader.TryRetrieve()
+}
+
+// panicStack summarizes the stack of a recovered panic, one function
+// and line per frame, skipping the runtime frames.
+func panicStack() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	lines := make([]string, 0, maxPanicFrames)
+	for len(lines) < maxPanicFrames {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			lines = append(lines, fmt.Sprintf("\t%s (%s:%d)", frame.Function, frame.File, frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
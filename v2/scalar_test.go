@@ -0,0 +1,104 @@
+package configloader
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestUnsignedErrors(t *testing.T) {
+	cases := []struct {
+		raw      string
+		expected string
+	}{
+		{"-1", "field 'Workers': value '-1' is not a valid unsigned integer"},
+		{"abc", "field 'Workers': value 'abc' is not a valid unsigned integer"},
+		{"65536", "field 'Workers': value '65536' overflows uint16"},
+	}
+	for _, test := range cases {
+		var config struct{ Workers uint16 }
+		_, err := NewConfigLoaderFor(&config).
+			AddHook(CreateTestHook(map[string]string{"Workers": test.raw})).
+			SafeRetrieve()
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("%s: expected %q, got %v", test.raw, test.expected, err)
+		}
+	}
+}
+
+func TestUnsignedBoundaries(t *testing.T) {
+	cases := []struct {
+		raw      string
+		expected interface{}
+	}{
+		{"0", uint8(0)},
+		{"255", uint8(math.MaxUint8)},
+		{"65535", uint16(math.MaxUint16)},
+		{"4294967295", uint32(math.MaxUint32)},
+		{strconv.FormatUint(math.MaxUint64, 10), uint64(math.MaxUint64)},
+	}
+	for _, test := range cases {
+		value := reflect.New(reflect.TypeOf(test.expected)).Elem()
+		if err := setScalar(value, test.raw); err != nil || value.Interface() != test.expected {
+			t.Errorf("%s: expected %v, got %v (%v)", test.raw, test.expected, value.Interface(), err)
+		}
+	}
+}
+
+func TestUnsignedOverflow(t *testing.T) {
+	cases := []struct {
+		raw    string
+		target interface{}
+	}{
+		{"256", uint8(0)},
+		{"4294967296", uint32(0)},
+		{"18446744073709551616", uint64(0)},
+	}
+	for _, test := range cases {
+		value := reflect.New(reflect.TypeOf(test.target)).Elem()
+		expected := "value '" + test.raw + "' overflows " + value.Type().String()
+		if err := setScalar(value, test.raw); err == nil || err.Error() != expected {
+			t.Errorf("%s: expected %q, got %v", test.raw, expected, err)
+		}
+	}
+}
+
+func TestFloatNotation(t *testing.T) {
+	cases := []struct {
+		raw      string
+		expected interface{}
+	}{
+		{"-1.5e3", float64(-1500)},
+		{"2.5E-2", float64(0.025)},
+		{"-0.5", float32(-0.5)},
+		{"3.4e38", float32(3.4e38)},
+		{"1e308", float64(1e308)},
+		{"+7", float32(7)},
+	}
+	for _, test := range cases {
+		value := reflect.New(reflect.TypeOf(test.expected)).Elem()
+		if err := setScalar(value, test.raw); err != nil || value.Interface() != test.expected {
+			t.Errorf("%s: expected %v, got %v (%v)", test.raw, test.expected, value.Interface(), err)
+		}
+	}
+}
+
+func TestFloatErrors(t *testing.T) {
+	cases := []struct {
+		raw      string
+		target   interface{}
+		expected string
+	}{
+		{"1e39", float32(0), "value '1e39' overflows float32"},
+		{"-1e39", float32(0), "value '-1e39' overflows float32"},
+		{"1e309", float64(0), "value '1e309' overflows float64"},
+		{"1.5x", float64(0), "value '1.5x' is not a valid number"},
+	}
+	for _, test := range cases {
+		value := reflect.New(reflect.TypeOf(test.target)).Elem()
+		if err := setScalar(value, test.raw); err == nil || err.Error() != test.expected {
+			t.Errorf("%s: expected %q, got %v", test.raw, test.expected, err)
+		}
+	}
+}
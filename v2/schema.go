@@ -0,0 +1,166 @@
+package configloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// GenerateJSONSchema returns a draft-07 JSON Schema describing the
+// JSON files CreateFileHook can load into target. It uses the same
+// keys as encoding/json, marks configRequired fields as required,
+// and adds the configDefault and configOneof values of each field.
+// Target must be a pointer to a struct.
+func GenerateJSONSchema(target interface{}) ([]byte, error) {
+	// Fail like loading does, for instance on recursive types.
+	err := foreachField(reflect.New(reflect.TypeOf(target).Elem()).Interface(), func(currentField) error {
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	schema, err := structSchema(reflect.TypeOf(target).Elem(), nil)
+	if err != nil {
+		return nil, err
+	}
+	schema["$schema"] = jsonSchemaDraft
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// structSchema describes typ. parents are the structs being described
+// around it, to report recursive types instead of looping forever.
+func structSchema(typ reflect.Type, parents []reflect.Type) (map[string]interface{}, error) {
+	for _, parent := range parents {
+		if parent == typ {
+			return nil, fmt.Errorf("recursive type %s is not supported", typ)
+		}
+	}
+	parents = append(append([]reflect.Type{}, parents...), typ)
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+	for i := 0; i < typ.NumField(); i++ {
+		field, err := expandConfigTag(typ.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if len(field.PkgPath) > 0 || field.Tag.Get("json") == "-" {
+			continue
+		}
+		schema, err := fieldSchema(field, parents)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		name := getJSONKey(field)
+		properties[name] = schema
+		if isTagEnabled(field.Tag, "configRequired") {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+func fieldSchema(field reflect.StructField, parents []reflect.Type) (map[string]interface{}, error) {
+	schema, err := typeSchema(field.Type, parents)
+	if err != nil {
+		return nil, err
+	}
+	if value, ok := field.Tag.Lookup("configDefault"); ok && !defaultReference.MatchString(value) {
+		parsed, err := parseTagValue(field, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid configDefault: %w", err)
+		}
+		schema["default"] = parsed
+	}
+	if options := getOneOf(field); len(options) > 0 {
+		enum := make([]interface{}, 0, len(options))
+		for _, option := range options {
+			parsed, err := parseTagValue(field, option)
+			if err != nil {
+				return nil, fmt.Errorf("invalid configOneof: %w", err)
+			}
+			enum = append(enum, parsed)
+		}
+		schema["enum"] = enum
+	}
+	return schema, nil
+}
+
+func typeSchema(typ reflect.Type, parents []reflect.Type) (map[string]interface{}, error) {
+	if typ == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	}
+	if typ == bigIntType {
+		return map[string]interface{}{"type": "integer"}, nil
+	}
+	if typ == bigFloatType {
+		return map[string]interface{}{"type": "string"}, nil
+	}
+	if typ == regexpType {
+		return map[string]interface{}{"type": "string", "format": "regex"}, nil
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Ptr:
+		return typeSchema(typ.Elem(), parents)
+	case reflect.Slice, reflect.Array:
+		items, err := typeSchema(typ.Elem(), parents)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		values, err := typeSchema(typ.Elem(), parents)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return structSchema(typ, parents)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+}
+
+// parseTagValue parses a tag value the same way setField would,
+// returning it as a value of the field type.
+func parseTagValue(field reflect.StructField, rawValue string) (interface{}, error) {
+	value := reflect.New(field.Type).Elem()
+	if err := setValue(value, rawValue, field.Tag); err != nil {
+		return nil, err
+	}
+	return value.Interface(), nil
+}
+
+// getOneOf returns the comma separated values of the configOneof tag.
+func getOneOf(field reflect.StructField) []string {
+	tag := field.Tag.Get("configOneof")
+	if len(tag) == 0 {
+		return nil
+	}
+	options := strings.Split(tag, ",")
+	for i, option := range options {
+		options[i] = strings.TrimSpace(option)
+	}
+	return options
+}
@@ -0,0 +1,23 @@
+package configloader
+
+// WithSecrets marks fields as secrets without tagging them, for
+// structs you can't or don't want to annotate. Fields are named by
+// their Go name, like "Password", or by their dotted path, like
+// "Database.Password". They are redacted like configSecret fields:
+// in conversion errors, verbose logs and the FieldInfo passed to
+// WithSkipFields. A field is a secret if either marks it, so a
+// configSecret:"false" tag does not unmark a listed field. Calling it
+// again adds to the previous names.
+func (loader *ConfigLoader) WithSecrets(names ...string) *ConfigLoader {
+	if loader.secrets == nil {
+		loader.secrets = make(map[string]bool)
+	}
+	for _, name := range names {
+		loader.secrets[name] = true
+	}
+	return loader
+}
+
+func (loader *ConfigLoader) isListedSecret(field currentField) bool {
+	return loader.secrets[field.original.Name] || loader.secrets[field.id()]
+}
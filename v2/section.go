@@ -0,0 +1,60 @@
+package configloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// baseSections are the sections of a sectioned file loaded under
+// the selected one, in order.
+var baseSections = []string{"default", "common"}
+
+// EnvSectionFileHook will load one section of a JSON file whose top
+// level keys are environments, like {"dev": {...}, "prod": {...}}.
+type EnvSectionFileHook struct {
+	file    string
+	section string
+}
+
+// CreateEnvSectionFileHook creates a hook which loads the object under
+// the section key of file, usually taken from a var like APP_ENV:
+//
+//	configloader.CreateEnvSectionFileHook("./config.json", os.Getenv("APP_ENV"))
+//
+// The "default" and "common" sections, if the file has them, are
+// loaded first, so the selected section only needs the keys that
+// differ. A missing selected section is an error.
+func CreateEnvSectionFileHook(file, section string) EnvSectionFileHook {
+	return EnvSectionFileHook{file: file, section: section}
+}
+
+func (hook EnvSectionFileHook) source() string {
+	return "file"
+}
+
+func (hook EnvSectionFileHook) run(loader *ConfigLoader) error {
+	data, err := os.ReadFile(hook.file)
+	if err != nil {
+		return fmt.Errorf("error while reading config file: %w", err)
+	}
+	sections := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return fmt.Errorf("error while decoding config file: %w", err)
+	}
+	selected, ok := sections[hook.section]
+	if !ok || len(hook.section) == 0 {
+		return fmt.Errorf("config file %s has no section '%s'", hook.file, hook.section)
+	}
+	for _, name := range baseSections {
+		if base, ok := sections[name]; ok && name != hook.section {
+			if err := loader.decodeJSON(base, false); err != nil {
+				return fmt.Errorf("error while decoding section '%s' of config file: %w", name, err)
+			}
+		}
+	}
+	if err := loader.decodeJSON(selected, false); err != nil {
+		return fmt.Errorf("error while decoding section '%s' of config file: %w", hook.section, err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package configloader
+
+import (
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// WithSetters makes hooks set unexported fields through a setter
+// method on the pointer to their struct, named Set followed by the
+// field name with its first letter in uppercase:
+//
+//	type Config struct {
+//		logLevel string
+//	}
+//
+//	func (config *Config) SetLogLevel(level string) error { ... }
+//
+// The setter receives the raw value from the source, as a string, and
+// may return an error, which fails like an invalid value. Setters can
+// return nothing too. Unexported fields without a setter are still
+// ignored. Only hooks setting fields from strings, like env vars,
+// params, defaults or key value sources, call setters: hooks decoding
+// whole JSON documents can't see unexported fields. OnFieldSet
+// callbacks receive the string passed to the setter.
+func (loader *ConfigLoader) WithSetters() *ConfigLoader {
+	loader.setters = true
+	return loader
+}
+
+// foreachSetterField walks the unexported fields of target which have
+// a setter. Their value is a string holding the last value passed to
+// the setter, so hooks can treat them like string fields.
+func foreachSetterField(target interface{}, runAction func(currentField) error) error {
+	return walkSetterFields(target, false, runAction)
+}
+
+// foreachSetterValue walks the same fields as foreachSetterField, but
+// their value is the one stored in the unexported field, which can be
+// read but not set. Validation uses it to check what setters stored.
+func foreachSetterValue(target interface{}, runAction func(currentField) error) error {
+	return walkSetterFields(target, true, runAction)
+}
+
+func walkSetterFields(target interface{}, stored bool, runAction func(currentField) error) error {
+	value := reflect.ValueOf(target).Elem()
+	for _, meta := range getTypeFields(value.Type()) {
+		if len(meta.original.PkgPath) == 0 || meta.tagErr != nil || meta.cycleErr != nil {
+			continue
+		}
+		last := len(meta.index) - 1
+		parent, commit := setterParent(value, meta.index[:last])
+		if !parent.CanSet() {
+			continue
+		}
+		setter, ok := findSetter(parent.Addr(), meta.original.Name)
+		if !ok {
+			continue
+		}
+		fieldValue := reflect.New(reflect.TypeOf("")).Elem()
+		if stored {
+			fieldValue = readUnexported(parent.Field(meta.index[last]))
+		}
+		err := runAction(currentField{
+			original: meta.original,
+			value:    fieldValue,
+			name:     meta.name,
+			path:     meta.path,
+			prefixes: meta.prefixes,
+			index:    meta.index[last],
+			commit:   commit,
+			setter:   setter,
+			origin:   meta.path,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readUnexported returns a readable view of an addressable unexported
+// field, since reflect refuses to Interface them.
+func readUnexported(value reflect.Value) reflect.Value {
+	return reflect.NewAt(value.Type(), unsafe.Pointer(value.UnsafeAddr())).Elem()
+}
+
+// setterParent returns the struct holding the field at index, which
+// fieldByIndex stops short of when it is behind a pointer. A nil
+// pointer gets a new struct, stored by the returned commit function.
+func setterParent(value reflect.Value, index []int) (reflect.Value, func()) {
+	parent, commit := fieldByIndex(value, index)
+	if parent.Kind() != reflect.Ptr {
+		return parent, commit
+	}
+	if !parent.CanSet() {
+		return reflect.Value{}, nil
+	}
+	if !parent.IsNil() {
+		return parent.Elem(), commit
+	}
+	allocated := reflect.New(parent.Type().Elem())
+	pointer, outer := parent, commit
+	return allocated.Elem(), func() {
+		pointer.Set(allocated)
+		if outer != nil {
+			outer()
+		}
+	}
+}
+
+// findSetter returns the Set method for the field name of the struct
+// pointer, if it takes a string and returns nothing or an error.
+func findSetter(pointer reflect.Value, name string) (reflect.Value, bool) {
+	first, size := utf8.DecodeRuneInString(name)
+	method := pointer.MethodByName("Set" + string(unicode.ToUpper(first)) + name[size:])
+	if !method.IsValid() {
+		return reflect.Value{}, false
+	}
+	typ := method.Type()
+	if typ.NumIn() != 1 || typ.In(0).Kind() != reflect.String {
+		return reflect.Value{}, false
+	}
+	if typ.NumOut() > 1 || (typ.NumOut() == 1 && typ.Out(0) != errorType) {
+		return reflect.Value{}, false
+	}
+	return method, true
+}
+
+// callSetter passes rawValue to the setter of field.
+func callSetter(field currentField, rawValue string) error {
+	field.value.SetString(rawValue)
+	argument := reflect.New(field.setter.Type().In(0)).Elem()
+	argument.SetString(rawValue)
+	results := field.setter.Call([]reflect.Value{argument})
+	if len(results) == 1 && !results[0].IsNil() {
+		return fieldError(field, results[0].Interface().(error))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package configloader
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type setterConfig struct {
+	level string `configRequired:"true" configOneof:"debug,info,warn"`
+}
+
+func (config *setterConfig) SetLevel(level string) error {
+	if level == "fail" {
+		return fmt.Errorf("unknown level '%s'", level)
+	}
+	config.level = level
+	return nil
+}
+
+func TestSetterFieldsCountForStrictTarget(t *testing.T) {
+	var config setterConfig
+	loader := NewConfigLoaderFor(&config).WithSetters().WithStrictTarget()
+	_, err := loader.AddHook(CreateTestHook(map[string]string{"level": "info"})).SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.level != "info" {
+		t.Errorf("expected level info, got %q", config.level)
+	}
+	if len(loader.Warnings()) != 0 {
+		t.Errorf("unexpected warnings %v", loader.Warnings())
+	}
+}
+
+func TestStrictTargetWithoutSetters(t *testing.T) {
+	var config setterConfig
+	_, err := NewConfigLoaderFor(&config).WithStrictTarget().SafeRetrieve()
+	if !errors.Is(err, ErrNoSettableFields) {
+		t.Errorf("expected ErrNoSettableFields, got %v", err)
+	}
+}
+
+func TestSetterFieldsAreValidated(t *testing.T) {
+	cases := []struct {
+		values   map[string]string
+		expected string
+	}{
+		{map[string]string{}, "invalid config: field level is required"},
+		{map[string]string{"level": "trace"}, "invalid config: field level must be one of debug, info, warn"},
+		{map[string]string{"level": "fail"}, "field 'level': unknown level 'fail'"},
+	}
+	for _, test := range cases {
+		var config setterConfig
+		_, err := NewConfigLoaderFor(&config).
+			WithSetters().
+			AddHook(CreateTestHook(test.values)).
+			SafeRetrieve()
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("%v: expected %q, got %v", test.values, test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package configloader
+
+import "reflect"
+
+// Snapshot returns a deep copy of the target, a pointer of the same
+// type, sharing no maps, slices or pointers with it. Take one after
+// each load and hand it to readers: loading again, for example after
+// Reset, changes the target but never a snapshot, so readers don't see
+// a half updated config. Snapshot itself reads the target, so don't
+// call it while a load is running. The copy costs as much as
+// allocating the whole config again, so for large structs take it once
+// per load instead of once per read. Unexported fields are copied as
+// they are.
+func (loader *ConfigLoader) Snapshot() interface{} {
+	return deepCopy(reflect.ValueOf(loader.target)).Interface()
+}
@@ -0,0 +1,74 @@
+package configloader
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SQLHook loads data from the key and value rows returned by a
+// database query, so settings can be edited from an admin UI.
+type SQLHook struct {
+	db     *sql.DB
+	query  string
+	naming NamingStrategy
+}
+
+// CreateSQLHook creates a hook which runs query on db. The query
+// must return two columns, a key and a value, like:
+//
+//	SELECT name, value FROM settings WHERE service = 'api'
+//
+// Keys are the field names, as used by params, unless you change
+// them with WithNaming or the loader WithNamingStrategy. NULL values
+// are skipped. Any database driver works, since only *sql.DB is used.
+func CreateSQLHook(db *sql.DB, query string) SQLHook {
+	return SQLHook{db: db, query: query}
+}
+
+// WithNaming changes how field names map to keys, overriding the
+// loader naming strategy.
+func (hook SQLHook) WithNaming(naming NamingStrategy) SQLHook {
+	hook.naming = naming
+	return hook
+}
+
+func (hook SQLHook) source() string {
+	return "sql"
+}
+
+func (hook SQLHook) run(loader *ConfigLoader) error {
+	values, err := hook.read()
+	if err != nil {
+		return err
+	}
+	naming := loader.namingFor(hook.naming)
+	return loader.foreachField(func(field currentField) error {
+		if value, ok := values[naming(field.name)]; ok {
+			return loader.setField(field, value)
+		}
+		return nil
+	})
+}
+
+func (hook SQLHook) read() (map[string]string, error) {
+	rows, err := hook.db.Query(hook.query)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying config: %w", err)
+	}
+	defer rows.Close()
+	values := make(map[string]string)
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("error while reading config row: %w", err)
+		}
+		if value.Valid {
+			values[key] = value.String
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while reading config rows: %w", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,177 @@
+package configloader
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+var errStrictQuoted = errors.New("WithQuotedValues and WithStrictTypes can't be used together")
+
+// WithStrictTypes makes the hook reject any value whose JSON type does
+// not match its field, like "8080" (a string) for an int field or 1 for
+// a bool one, before anything is set. Types which decode themselves,
+// like time.Time, accept what they decode, and durations accept both
+// strings and numbers. It can't be combined with WithQuotedValues.
+func (hook ConfigFileHook) WithStrictTypes() ConfigFileHook {
+	hook.strictTypes = true
+	return hook
+}
+
+// WithStrictTypes makes the hook reject values whose JSON type does not
+// match their field, instead of converting them: strings for number and
+// bool fields, numbers and bools for string fields, and single values
+// for list fields. Durations and types with a converter accept any
+// value, since they parse strings and numbers themselves.
+func (hook FlatFileHook) WithStrictTypes() FlatFileHook {
+	hook.strictTypes = true
+	return hook
+}
+
+// WithStrictTypes works like FlatFileHook.WithStrictTypes.
+func (hook JSONPathHook) WithStrictTypes() JSONPathHook {
+	hook.strictTypes = true
+	return hook
+}
+
+// checkJSONDocument checks the types of every value of data against
+// the fields of target they are decoded into.
+func checkJSONDocument(target interface{}, data []byte) error {
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return err
+	}
+	return checkJSONTypes(reflect.TypeOf(target), document, "")
+}
+
+func checkJSONTypes(typ reflect.Type, value interface{}, path string) error {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	expected := expectedJSONType(typ)
+	if value == nil || len(expected) == 0 {
+		return nil
+	}
+	if got := jsonTypeName(value); got != expected {
+		return fmt.Errorf("field '%s': expected %s, got %s", path, expected, got)
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		object := value.(map[string]interface{})
+		for i := 0; i < typ.NumField(); i++ {
+			field, _ := expandConfigTag(typ.Field(i))
+			if len(field.PkgPath) > 0 {
+				continue
+			}
+			for key, current := range object {
+				if strings.EqualFold(key, getJSONKey(field)) || strings.EqualFold(key, getJSONName(field)) {
+					if err := checkJSONTypes(field.Type, current, joinJSONPath(path, key)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i, item := range value.([]interface{}) {
+			if err := checkJSONTypes(typ.Elem(), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for key, item := range value.(map[string]interface{}) {
+			if err := checkJSONTypes(typ.Elem(), item, joinJSONPath(path, key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkJSONFieldType checks the type of a value read by the flat file
+// and JSON path hooks, which set fields from strings.
+func checkJSONFieldType(typ reflect.Type, value interface{}) error {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if _, ok := lookupConverter(typ); ok || typ == durationType || value == nil {
+		return nil
+	}
+	expected := expectedJSONType(typ)
+	if len(expected) == 0 {
+		return nil
+	}
+	if got := jsonTypeName(value); got != expected {
+		return fmt.Errorf("expected %s, got %s", expected, got)
+	}
+	if list, ok := value.([]interface{}); ok {
+		for i, item := range list {
+			if err := checkJSONFieldType(typ.Elem(), item); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// expectedJSONType returns the JSON type, with its article, that
+// fields of type typ are decoded from, or "" if they accept any.
+func expectedJSONType(typ reflect.Type) string {
+	if typ.Implements(jsonUnmarshalerType) || reflect.PtrTo(typ).Implements(jsonUnmarshalerType) || isTextUnmarshaler(typ) || typ == durationType {
+		return ""
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			// encoding/json reads []byte from base64 strings.
+			return "a string"
+		}
+		return "an array"
+	case reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return ""
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "a string"
+	case json.Number, float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return "null"
+}
+
+func joinJSONPath(path, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,60 @@
+package configloader
+
+import (
+	"fmt"
+	"strings"
+)
+
+// String lists the hooks of the loader in the order they run, with
+// their type and source, to check the precedence of a setup before
+// calling Retrieve. Hooks which already ran are listed first and
+// marked. It does not change the loader.
+func (loader *ConfigLoader) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "ConfigLoader for %T", loader.target)
+	position := 1
+	for _, hook := range loader.ran {
+		fmt.Fprintf(&builder, "\n  %d. %s, ran", position, describeHook(hook))
+		position++
+	}
+	for _, hook := range loader.pendingHooks() {
+		fmt.Fprintf(&builder, "\n  %d. %s", position, describeHook(hook))
+		position++
+	}
+	return builder.String()
+}
+
+// pendingHooks returns the queued hooks in order, leaving the queue
+// as it was.
+func (loader *ConfigLoader) pendingHooks() []Hook {
+	pending := loader.takeHooks()
+	for _, hook := range pending {
+		loader.hooks.Enqueue(hook)
+	}
+	return pending
+}
+
+// takeHooks empties the queue, returning its hooks in order.
+func (loader *ConfigLoader) takeHooks() []Hook {
+	pending := make([]Hook, 0, loader.hooks.Len())
+	for loader.hooks.Len() > 0 {
+		pending = append(pending, loader.hooks.Dequeue().(Hook))
+	}
+	return pending
+}
+
+// describeHook names a hook by its type and source, like
+// "EnvHook (env)", adding the layer of named file hooks. Optional
+// and required hooks show the hook they wrap.
+func describeHook(hook Hook) string {
+	if optional, ok := hook.(OptionalHook); ok {
+		return fmt.Sprintf("Optional %s", describeHook(optional.hook))
+	}
+	if required, ok := hook.(RequiredHook); ok {
+		return fmt.Sprintf("Required %s", describeHook(required.hook))
+	}
+	if layer := hookLayer(hook); len(layer) > 0 {
+		return fmt.Sprintf("%s (%s, layer %s)", hookName(hook), hook.source(), layer)
+	}
+	return fmt.Sprintf("%s (%s)", hookName(hook), hook.source())
+}
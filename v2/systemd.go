@@ -0,0 +1,58 @@
+package configloader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SystemdCredsHook loads data from the credentials systemd passes
+// to a service, one file per credential in $CREDENTIALS_DIRECTORY.
+type SystemdCredsHook struct {
+	naming NamingStrategy
+}
+
+// CreateSystemdCredsHook creates a hook which reads the credential
+// named like each field, as used by params, from the directory in
+// $CREDENTIALS_DIRECTORY. Trailing newlines are removed. Change the
+// credential names with WithNaming or the loader WithNamingStrategy.
+// If the variable is not set, as when not running under systemd, the
+// hook does nothing.
+func CreateSystemdCredsHook() SystemdCredsHook {
+	return SystemdCredsHook{}
+}
+
+// WithNaming changes how field names map to credential names,
+// overriding the loader naming strategy.
+func (hook SystemdCredsHook) WithNaming(naming NamingStrategy) SystemdCredsHook {
+	hook.naming = naming
+	return hook
+}
+
+func (hook SystemdCredsHook) source() string {
+	return "systemd"
+}
+
+func (hook SystemdCredsHook) run(loader *ConfigLoader) error {
+	dir := os.Getenv("CREDENTIALS_DIRECTORY")
+	if len(dir) == 0 {
+		return nil
+	}
+	naming := loader.namingFor(hook.naming)
+	return loader.foreachField(func(field currentField) error {
+		name := naming(field.name)
+		if len(name) == 0 || strings.ContainsAny(name, `/\`) {
+			return nil
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error while reading credential %s: %w", name, err)
+		}
+		return loader.setField(field, strings.TrimRight(string(content), "\r\n"))
+	})
+}
@@ -0,0 +1,27 @@
+package configloader
+
+// TestHook loads data from a map. It is meant for tests, where
+// you want known values without touching env vars or files.
+type TestHook struct {
+	values map[string]string
+}
+
+// CreateTestHook creates a hook which loads data from values.
+// Keys are field names, following the same rules as ParamsHook:
+// configName (or the field name) preceded by its struct configPrefix.
+func CreateTestHook(values map[string]string) TestHook {
+	return TestHook{values: values}
+}
+
+func (hook TestHook) source() string {
+	return "test"
+}
+
+func (hook TestHook) run(loader *ConfigLoader) error {
+	return loader.foreachField(func(field currentField) error {
+		if value, ok := hook.values[field.name]; ok {
+			return loader.setField(field, value)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,59 @@
+package configloader
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+)
+
+// isTextUnmarshaler reports whether values of typ, or pointers to
+// them, implement encoding.TextUnmarshaler.
+func isTextUnmarshaler(typ reflect.Type) bool {
+	return typ.Implements(textUnmarshalerType) || reflect.PtrTo(typ).Implements(textUnmarshalerType)
+}
+
+// setText sets fields whose type implements encoding.TextUnmarshaler,
+// like netip.Addr, by calling UnmarshalText with rawValue. Pointer
+// receivers are reached through the address of value. It reports
+// false if the type does not implement it.
+func setText(value reflect.Value, rawValue string) (bool, error) {
+	var unmarshaler encoding.TextUnmarshaler
+	switch {
+	case value.Kind() != reflect.Ptr && value.CanAddr() && reflect.PtrTo(value.Type()).Implements(textUnmarshalerType):
+		unmarshaler = value.Addr().Interface().(encoding.TextUnmarshaler)
+	case value.Kind() != reflect.Ptr && value.Type().Implements(textUnmarshalerType):
+		unmarshaler = value.Interface().(encoding.TextUnmarshaler)
+	default:
+		return false, nil
+	}
+	if err := unmarshaler.UnmarshalText([]byte(rawValue)); err != nil {
+		return true, fmt.Errorf("value '%s' is not a valid %s: %w", rawValue, value.Type(), err)
+	}
+	return true, nil
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// marshalText returns the text of values whose type implements
+// encoding.TextMarshaler, so they are written back in the form
+// UnmarshalText reads. It reports false for other values, nil
+// pointers and values failing to marshal.
+func marshalText(value reflect.Value) (string, bool) {
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return "", false
+	}
+	var marshaler encoding.TextMarshaler
+	switch {
+	case value.Type().Implements(textMarshalerType):
+		marshaler = value.Interface().(encoding.TextMarshaler)
+	case value.CanAddr() && reflect.PtrTo(value.Type()).Implements(textMarshalerType):
+		marshaler = value.Addr().Interface().(encoding.TextMarshaler)
+	default:
+		return "", false
+	}
+	text, err := marshaler.MarshalText()
+	if err != nil {
+		return "", false
+	}
+	return string(text), true
+}
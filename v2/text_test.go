@@ -0,0 +1,93 @@
+package configloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// textLevel implements both encoding.TextMarshaler and
+// json.Marshaler, with different results, to show which one wins.
+// As text it is a name, and as JSON a number.
+type textLevel int
+
+var textLevelNames = []string{"debug", "info", "warn"}
+
+func (level textLevel) MarshalText() ([]byte, error) {
+	return []byte(textLevelNames[level]), nil
+}
+
+func (level *textLevel) UnmarshalText(text []byte) error {
+	for i, name := range textLevelNames {
+		if name == string(text) {
+			*level = textLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level %s", text)
+}
+
+func (level textLevel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int(level))
+}
+
+func (level *textLevel) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*int)(level))
+}
+
+type textConfig struct {
+	Level  textLevel
+	Levels []textLevel
+}
+
+func TestFormatValueUsesMarshalText(t *testing.T) {
+	level := textLevel(2)
+	if got := formatValue(reflect.ValueOf(level)); got != "warn" {
+		t.Errorf("expected warn, got %s", got)
+	}
+	if got := formatValue(reflect.ValueOf(&level)); got != "warn" {
+		t.Errorf("expected warn through a pointer, got %s", got)
+	}
+}
+
+func TestDefaultsReferenceUsesMarshalText(t *testing.T) {
+	var config struct {
+		Level textLevel
+		Label string `configDefault:"level=${Level}"`
+	}
+	_, err := NewConfigLoaderFor(&config).
+		AddHook(CreateTestHook(map[string]string{"Level": "info"})).
+		AddHook(CreateDefaultsHook()).
+		SafeRetrieve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Level != 1 || config.Label != "level=info" {
+		t.Errorf("unexpected config %+v", config)
+	}
+}
+
+func TestWriteJSONPrefersMarshalJSON(t *testing.T) {
+	config := textConfig{Level: 2, Levels: []textLevel{0, 1}}
+	path := writeTestFile(t, "config.json", "")
+	if err := NewConfigLoaderFor(&config).WriteJSON(path, false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != `{"Level":2,"Levels":[0,1]}` {
+		t.Errorf("expected MarshalJSON output, got %s", got)
+	}
+	var loaded textConfig
+	if _, err := NewConfigLoaderFor(&loaded).AddHook(CreateFileHook(path)).SafeRetrieve(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, config) {
+		t.Errorf("expected %+v to read back, got %+v", config, loaded)
+	}
+}
@@ -0,0 +1,29 @@
+package configloader
+
+import "time"
+
+// HookCallback is called after a hook runs, with the type of the
+// hook (like "EnvHook"), how long it took and the error it returned.
+type HookCallback func(hook string, duration time.Duration, err error)
+
+// OnHookRun registers a callback called after each hook runs, to
+// measure slow sources, like remote ones, or export the timings as
+// metrics. Hooks are only timed when a callback is registered.
+func (loader *ConfigLoader) OnHookRun(callback HookCallback) *ConfigLoader {
+	loader.timings = append(loader.timings, callback)
+	return loader
+}
+
+// runTimed runs hook, calling the OnHookRun callbacks when it ends.
+func (loader *ConfigLoader) runTimed(hook Hook) error {
+	if len(loader.timings) == 0 {
+		return hook.run(loader)
+	}
+	started := time.Now()
+	err := hook.run(loader)
+	duration := time.Since(started)
+	for _, callback := range loader.timings {
+		callback(hookName(hook), duration, err)
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+package configloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const defaultDiscriminator = "type"
+
+var (
+	registeredTypesMutex sync.RWMutex
+	registeredTypes      = make(map[string]reflect.Type)
+)
+
+// RegisterType registers a concrete type under a name, so fields of
+// an interface type can be loaded from JSON files. The JSON object of
+// the field must have a "type" key (or the key set in the field
+// configDiscriminator tag) with the name of the registered type:
+//
+//	type Config struct {
+//		Driver Driver `configDiscriminator:"kind"`
+//	}
+//
+//	configloader.RegisterType("postgres", PostgresDriver{})
+//
+// With {"Driver": {"kind": "postgres", "Host": "db"}} the field will
+// hold a *PostgresDriver decoded from that object. Names are global,
+// so register each one only once. Unknown names are an error.
+func RegisterType(name string, value interface{}) {
+	typ := reflect.TypeOf(value)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	registeredTypesMutex.Lock()
+	defer registeredTypesMutex.Unlock()
+	registeredTypes[name] = typ
+}
+
+func hasRegisteredTypes() bool {
+	registeredTypesMutex.RLock()
+	defer registeredTypesMutex.RUnlock()
+	return len(registeredTypes) > 0
+}
+
+func lookupRegisteredType(name string) (reflect.Type, bool) {
+	registeredTypesMutex.RLock()
+	defer registeredTypesMutex.RUnlock()
+	typ, ok := registeredTypes[name]
+	return typ, ok
+}
+
+// prepareInterfaces fills interface fields of target with pointers
+// to the types named by their discriminators in data. Then
+// encoding/json decodes each object into the pointer it finds.
+func prepareInterfaces(target interface{}, data []byte) error {
+	document := make(map[string]interface{})
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	return prepareInterfacesValue(reflect.ValueOf(target).Elem(), document)
+}
+
+func prepareInterfacesValue(value reflect.Value, document map[string]interface{}) error {
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldValue := value.Field(i)
+		if !fieldValue.CanSet() {
+			continue
+		}
+		object, ok := lookupJSONKey(document, getJSONName(field)).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			if err := prepareInterfacesValue(fieldValue, object); err != nil {
+				return err
+			}
+		case reflect.Interface:
+			if err := prepareInterface(field, fieldValue, object); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func prepareInterface(field reflect.StructField, value reflect.Value, object map[string]interface{}) error {
+	discriminator := getTagOr(field.Tag, "configDiscriminator", defaultDiscriminator)
+	name, ok := object[discriminator].(string)
+	if !ok {
+		return fmt.Errorf("missing '%s' key for field %s", discriminator, field.Name)
+	}
+	typ, ok := lookupRegisteredType(name)
+	if !ok {
+		return fmt.Errorf("unknown type '%s' for field %s", name, field.Name)
+	}
+	instance := reflect.New(typ)
+	if !instance.Type().Implements(field.Type) {
+		return fmt.Errorf("type '%s' does not implement %s for field %s", name, field.Type, field.Name)
+	}
+	value.Set(instance)
+	return nil
+}
+
+// getJSONName returns the key encoding/json uses for field.
+func getJSONName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if len(name) > 0 && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+// lookupJSONKey finds key in document, preferring an exact match but
+// ignoring case otherwise, like encoding/json does.
+func lookupJSONKey(document map[string]interface{}, key string) interface{} {
+	if value, ok := document[key]; ok {
+		return value
+	}
+	for current, value := range document {
+		if strings.EqualFold(current, key) {
+			return value
+		}
+	}
+	return nil
+}
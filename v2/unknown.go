@@ -0,0 +1,90 @@
+package configloader
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UnknownKeys returns the dotted paths of the keys in document which
+// don't match any field of target, like {"db": {"hots": "x"}} giving
+// "db.hots". Keys are matched with the JSON name of each field,
+// ignoring case, as encoding/json does. Use it to reject config files
+// that drifted from the struct, on documents decoded from JSON:
+//
+//	document := make(map[string]interface{})
+//	json.Unmarshal(data, &document)
+//	if unknown := configloader.UnknownKeys(document, &MyConfig{}); len(unknown) > 0 {
+//		log.Fatalln("unknown config keys:", unknown)
+//	}
+//
+// The paths are sorted. Target can be a struct or a pointer to one.
+func UnknownKeys(document map[string]interface{}, target interface{}) []string {
+	unknown := make([]string, 0)
+	unknown = findUnknownKeys("", document, reflect.TypeOf(target), unknown)
+	sort.Strings(unknown)
+	return unknown
+}
+
+func findUnknownKeys(prefix string, document map[string]interface{}, typ reflect.Type, unknown []string) []string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	fields := getJSONFields(typ)
+	for key, value := range document {
+		path := key
+		if len(prefix) > 0 {
+			path = fmt.Sprintf("%s.%s", prefix, key)
+		}
+		field, ok := matchJSONField(fields, key)
+		if !ok {
+			unknown = append(unknown, path)
+			continue
+		}
+		nested, isObject := value.(map[string]interface{})
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if isObject && fieldType.Kind() == reflect.Struct && !isValueStruct(fieldType) {
+			unknown = findUnknownKeys(path, nested, fieldType, unknown)
+		}
+	}
+	return unknown
+}
+
+// getJSONFields returns the fields encoding/json can set in typ,
+// including the ones promoted from embedded structs.
+func getJSONFields(typ reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field, _ := expandConfigTag(typ.Field(i))
+		if field.Tag.Get("json") == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && len(field.Tag.Get("json")) == 0 {
+			fields = append(fields, getJSONFields(field.Type)...)
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func matchJSONField(fields []reflect.StructField, key string) (reflect.StructField, bool) {
+	for _, field := range fields {
+		if getJSONKey(field) == key {
+			return field, true
+		}
+	}
+	for _, field := range fields {
+		if strings.EqualFold(getJSONKey(field), key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,138 @@
+package configloader
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ValidationError holds every validation failure found
+// after loading a config.
+type ValidationError struct {
+	Errors []error
+}
+
+func (err ValidationError) Error() string {
+	messages := make([]string, 0, len(err.Errors))
+	for _, current := range err.Errors {
+		messages = append(messages, current.Error())
+	}
+	return fmt.Sprintf("invalid config: %s", strings.Join(messages, "; "))
+}
+
+// validate checks the validation tags of the target once all hooks
+// ran. A field is considered set when it is not its zero value.
+//
+//   - configRequired:"true" fails when the field is not set.
+//   - configRequiredWith:"KeyFile,CaFile" fails when the field is not set
+//     but any of the listed fields is. Names are looked up among the
+//     fields of the same struct first, and then as full params names.
+//   - configOneof:"debug,info,warn" fails when the field is set to a value
+//     not listed.
+//   - configRequiredIf:"StorageType=postgres" fails when the field is not
+//     set but StorageType is postgres. StorageType is looked up like the
+//     fields of configRequiredWith.
+//
+// Fields excluded with WithSkipFields are not validated. With
+// WithSetters, fields with a setter are validated by the value the
+// setter stored in them.
+func (loader *ConfigLoader) validate() error {
+	fields := make([]currentField, 0)
+	collect := func(field currentField) error {
+		if !loader.isSkipped(field) {
+			fields = append(fields, field)
+		}
+		return nil
+	}
+	foreachField(loader.target, collect)
+	if loader.setters {
+		foreachSetterValue(loader.target, collect)
+	}
+	errors := make([]error, 0)
+	for _, field := range fields {
+		if isTagEnabled(field.original.Tag, "configRequired") && field.value.IsZero() {
+			errors = append(errors, fmt.Errorf("field %s is required", field.name))
+		}
+		if err := validateOneOf(field); err != nil {
+			errors = append(errors, err)
+		}
+		if err := validateRequiredIf(fields, field); err != nil {
+			errors = append(errors, err)
+		}
+		if with := field.original.Tag.Get("configRequiredWith"); len(with) > 0 && field.value.IsZero() {
+			for _, name := range strings.Split(with, ",") {
+				other, ok := findRelatedField(fields, field, strings.TrimSpace(name))
+				if !ok {
+					errors = append(errors, fmt.Errorf("field %s: configRequiredWith references unknown field %s", field.name, name))
+					continue
+				}
+				if !other.value.IsZero() {
+					errors = append(errors, fmt.Errorf("field %s is required when %s is set", field.name, other.name))
+				}
+			}
+		}
+	}
+	if len(errors) > 0 {
+		return ValidationError{Errors: errors}
+	}
+	return nil
+}
+
+func validateOneOf(field currentField) error {
+	options := getOneOf(field.original)
+	if len(options) == 0 || field.value.IsZero() {
+		return nil
+	}
+	for _, option := range options {
+		parsed, err := parseTagValue(field.original, option)
+		if err != nil {
+			return fmt.Errorf("field %s: invalid configOneof: %w", field.name, err)
+		}
+		if reflect.DeepEqual(parsed, field.value.Interface()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("field %s must be one of %s", field.name, strings.Join(options, ", "))
+}
+
+func validateRequiredIf(fields []currentField, field currentField) error {
+	condition := field.original.Tag.Get("configRequiredIf")
+	if len(condition) == 0 || !field.value.IsZero() {
+		return nil
+	}
+	pair := strings.SplitN(condition, "=", 2)
+	if len(pair) != 2 {
+		return fmt.Errorf("field %s: invalid configRequiredIf '%s': expected Field=value", field.name, condition)
+	}
+	name := strings.TrimSpace(pair[0])
+	other, ok := findRelatedField(fields, field, name)
+	if !ok {
+		return fmt.Errorf("field %s: configRequiredIf references unknown field %s", field.name, name)
+	}
+	expected, err := parseTagValue(other.original, strings.TrimSpace(pair[1]))
+	if err != nil {
+		return fmt.Errorf("field %s: invalid configRequiredIf: %w", field.name, err)
+	}
+	if reflect.DeepEqual(expected, other.value.Interface()) {
+		return fmt.Errorf("field %s is required when %s is %s", field.name, other.name, strings.TrimSpace(pair[1]))
+	}
+	return nil
+}
+
+// findRelatedField looks for a field named name next to field, or
+// anywhere in the struct if there is no such sibling.
+func findRelatedField(fields []currentField, field currentField, name string) (currentField, bool) {
+	parent := strings.Join(field.path[:len(field.path)-1], ".")
+	for _, other := range fields {
+		otherParent := strings.Join(other.path[:len(other.path)-1], ".")
+		if otherParent == parent && (other.path[len(other.path)-1] == name || other.original.Name == name) {
+			return other, true
+		}
+	}
+	for _, other := range fields {
+		if other.name == name {
+			return other, true
+		}
+	}
+	return currentField{}, false
+}
@@ -0,0 +1,34 @@
+package configloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// WriteJSON writes the target to a JSON file at path, for tools that
+// edit the config, like setup wizards. Keys are the names CreateFileHook
+// reads (the json tag or the field name) and values are encoded by
+// encoding/json, so the file loads back into the same values. Types
+// implementing encoding.TextMarshaler are written with MarshalText,
+// which UnmarshalText reads back, unless they implement json.Marshaler
+// too: then MarshalJSON wins, as in encoding/json. With pretty set, the
+// file is indented. JSON has no comments, so there are none to keep.
+// The file is created with 0600 permissions, because it may hold
+// secrets, and replaced if it exists.
+func (loader *ConfigLoader) WriteJSON(path string, pretty bool) error {
+	var data []byte
+	var err error
+	if pretty {
+		data, err = json.MarshalIndent(loader.target, "", "  ")
+	} else {
+		data, err = json.Marshal(loader.target)
+	}
+	if err != nil {
+		return fmt.Errorf("error while encoding config: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("error while writing config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package configloader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// XDGFileHook will load data from a JSON config file in the user
+// config directory, following the XDG Base Directory spec.
+type XDGFileHook struct {
+	app  string
+	file string
+}
+
+// CreateXDGFileHook creates a hook which loads fileName from the
+// config directory of appName, like CreateFileHook. The first of
+// these files that exists is loaded:
+//
+//  1. $XDG_CONFIG_HOME/appName/fileName
+//  2. ~/.config/appName/fileName, the default for $XDG_CONFIG_HOME
+//  3. the platform user config directory, as returned by
+//     os.UserConfigDir (~/Library/Application Support on macOS,
+//     %AppData% on Windows)
+//  4. appName/fileName in each directory of $XDG_CONFIG_DIRS, or
+//     /etc/xdg if it is not set
+//
+// If none exists, the hook does nothing.
+func CreateXDGFileHook(appName, fileName string) XDGFileHook {
+	return XDGFileHook{app: appName, file: fileName}
+}
+
+func (hook XDGFileHook) source() string {
+	return "file"
+}
+
+func (hook XDGFileHook) run(loader *ConfigLoader) error {
+	for _, path := range hook.candidates() {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return CreateFileHook(path).run(loader)
+		}
+	}
+	return nil
+}
+
+func (hook XDGFileHook) candidates() []string {
+	dirs := make([]string, 0)
+	if home := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(home) {
+		dirs = append(dirs, home)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config"))
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	systemDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if len(systemDirs) == 0 {
+		systemDirs = "/etc/xdg"
+	}
+	for _, dir := range strings.Split(systemDirs, string(os.PathListSeparator)) {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	paths := make([]string, 0, len(dirs))
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		path := filepath.Join(dir, hook.app, hook.file)
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,46 @@
+package configloader
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithNoImplicitZeros makes loading fail if some field is left at its
+// zero value without any hook setting it, listing those fields. A
+// field which a hook sets to zero, like CONFIG_RETRIES=0 or
+// "Retries": 0 in a JSON file, passes. Fields excluded with
+// WithSkipFields are not checked.
+func (loader *ConfigLoader) WithNoImplicitZeros() *ConfigLoader {
+	loader.noZeros = true
+	return loader
+}
+
+// recordWritten marks field as set by some hook.
+func (loader *ConfigLoader) recordWritten(field currentField) {
+	if !loader.noZeros {
+		return
+	}
+	if loader.written == nil {
+		loader.written = make(map[string]bool)
+	}
+	loader.written[field.id()] = true
+}
+
+// checkImplicitZeros returns an error naming the fields with a zero
+// value that no hook set.
+func (loader *ConfigLoader) checkImplicitZeros() error {
+	if !loader.noZeros {
+		return nil
+	}
+	missing := make([]string, 0)
+	foreachField(loader.target, func(field currentField) error {
+		if !loader.isSkipped(field) && field.value.IsZero() && !loader.written[field.id()] {
+			missing = append(missing, field.name)
+		}
+		return nil
+	})
+	if len(missing) > 0 {
+		return fmt.Errorf("fields not set by any source: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
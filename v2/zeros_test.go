@@ -0,0 +1,43 @@
+package configloader
+
+import (
+	"strings"
+	"testing"
+)
+
+type zerosConfig struct {
+	Name    string
+	Retries int
+	DB      struct {
+		Port int `json:"port"`
+	}
+}
+
+func TestNoImplicitZerosAcceptsExplicitZeroInFile(t *testing.T) {
+	file := writeTestFile(t, "config.json", `{"Retries": 0, "Name": "x", "DB": {"port": 0}}`)
+	var config zerosConfig
+	_, err := NewConfigLoaderFor(&config).WithNoImplicitZeros().AddHook(CreateFileHook(file)).SafeRetrieve()
+	if err != nil {
+		t.Fatalf("expected explicit zeros to pass, got %v", err)
+	}
+}
+
+func TestNoImplicitZerosListsMissingFields(t *testing.T) {
+	file := writeTestFile(t, "config.json", `{"Name": "x"}`)
+	var config zerosConfig
+	_, err := NewConfigLoaderFor(&config).WithNoImplicitZeros().AddHook(CreateFileHook(file)).SafeRetrieve()
+	if err == nil || !strings.Contains(err.Error(), "fields not set by any source: Retries, Port") {
+		t.Fatalf("expected Retries and Port to be missing, got %v", err)
+	}
+}
+
+func TestNoImplicitZerosAcceptsExplicitZeroInEnv(t *testing.T) {
+	setTestEnv(t, "CONFIG_NAME", "x")
+	setTestEnv(t, "CONFIG_RETRIES", "0")
+	setTestEnv(t, "CONFIG_PORT", "0")
+	var config zerosConfig
+	_, err := NewConfigLoaderFor(&config).WithNoImplicitZeros().AddHook(CreateEnvHook()).SafeRetrieve()
+	if err != nil {
+		t.Fatalf("expected explicit zeros to pass, got %v", err)
+	}
+}